@@ -0,0 +1,139 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"context"
+	"syscall/js"
+)
+
+// AbortController wraps a browser AbortController.
+type AbortController struct {
+	value js.Value
+}
+
+// NewAbortController creates a new browser AbortController.
+func NewAbortController() *AbortController {
+	return &AbortController{value: js.Global().Get("AbortController").New()}
+}
+
+// Signal returns the signal of the controller, which can be attached to requests using WithSignal.
+func (c *AbortController) Signal() AbortSignal {
+	return AbortSignal{value: c.value.Get("signal")}
+}
+
+// Abort aborts all requests with the signal of this controller. Their callbacks receive ErrAborted.
+func (c *AbortController) Abort() {
+	c.value.Call("abort")
+}
+
+// AbortSignal wraps a browser AbortSignal.
+type AbortSignal struct {
+	value js.Value
+}
+
+// Aborted returns true, if the signal has been aborted.
+func (s AbortSignal) Aborted() bool {
+	return s.value.Get("aborted").Bool()
+}
+
+// JSValue returns the browser AbortSignal.
+func (s AbortSignal) JSValue() js.Value {
+	return s.value
+}
+
+// WithSignal attaches the signal to the request, so that aborting the signal also aborts the request and the
+// callback receives ErrAborted. The signal is bridged into the context of the request, thus it works with
+// any transport. The listener of the signal is removed, after the request has been completed.
+func WithSignal(signal AbortSignal) Option {
+	return func(b *builder) error {
+		b.scopes = append(b.scopes, signalScope(signal))
+
+		return nil
+	}
+}
+
+// SignalOnEvent aborts the request, when the given event is dispatched to the target, e.g. a "click" of a cancel
+// button or a custom DOM event. The callback receives ErrAborted, just like with WithSignal. The listener is
+// removed, after the request has been completed.
+func SignalOnEvent(target js.Value, event string) Option {
+	return func(b *builder) error {
+		b.scopes = append(b.scopes, func(ctx context.Context) (context.Context, func()) {
+			controller := NewAbortController()
+
+			listener := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+				controller.Abort()
+
+				return nil
+			})
+
+			target.Call("addEventListener", event, listener)
+
+			ctx, release := signalScope(controller.Signal())(ctx)
+
+			return ctx, func() {
+				target.Call("removeEventListener", event, listener)
+				listener.Release()
+				release()
+			}
+		})
+
+		return nil
+	}
+}
+
+// signalScope bridges the signal into the context of a request, until the returned function is invoked.
+func signalScope(signal AbortSignal) func(ctx context.Context) (context.Context, func()) {
+	return func(ctx context.Context) (context.Context, func()) {
+		ctx, cancel := context.WithCancel(ctx)
+
+		listener := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			cancel()
+
+			return nil
+		})
+
+		signal.value.Call("addEventListener", "abort", listener)
+
+		if signal.Aborted() {
+			cancel()
+		}
+
+		return &signalContext{Context: ctx, signal: signal}, func() {
+			signal.value.Call("removeEventListener", "abort", listener)
+			listener.Release()
+			cancel()
+		}
+	}
+}
+
+// signalContext reports ErrAborted instead of context.Canceled, if it has been cancelled by its signal.
+type signalContext struct {
+	context.Context
+	signal AbortSignal
+}
+
+// Err returns ErrAborted, if the signal has been aborted.
+func (c *signalContext) Err() error {
+	err := c.Context.Err()
+	if err != nil && c.signal.Aborted() {
+		return ErrAborted
+	}
+
+	return err //nolint:wrapcheck
+}
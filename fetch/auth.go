@@ -0,0 +1,59 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import "net/http"
+
+// WithAuthRefresh replays the request once with a new bearer token, if the response is 401 Unauthorized. The token
+// is obtained by calling refresh from the goroutine of the request, so it may block, e.g. to perform a synchronous
+// refresh request. If refresh fails, its error is passed to the callback. If the replayed request is also
+// unauthorized, its response is passed to the callback, so a loop is impossible. The body of the request is buffered,
+// so that it can be sent again.
+func WithAuthRefresh(refresh func() (token string, err error)) Option {
+	return func(b *builder) error {
+		b.rewindable = true
+		b.wrap = append(b.wrap, func(client *http.Client, req *http.Request, next callback) callback {
+			return func(res *http.Response, err error) {
+				if err != nil || res.StatusCode != http.StatusUnauthorized {
+					next(res, err)
+
+					return
+				}
+
+				_ = Drain(res)
+
+				token, err := refresh()
+				if err != nil {
+					next(nil, err)
+
+					return
+				}
+
+				replay, err := rewind(req)
+				if err != nil {
+					next(nil, err)
+
+					return
+				}
+
+				replay = strip(replay)
+				replay.Header.Set("Authorization", "Bearer "+token)
+				Request(client, replay, next)
+			}
+		})
+
+		return nil
+	}
+}
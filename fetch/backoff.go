@@ -0,0 +1,91 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// A BackoffStrategy calculates the delay before the next attempt, after the given attempt has failed. The first
+// attempt is 1.
+type BackoffStrategy interface {
+	Next(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same duration after each attempt.
+type ConstantBackoff time.Duration
+
+// Next returns the constant duration.
+func (b ConstantBackoff) Next(attempt int) time.Duration {
+	return time.Duration(b)
+}
+
+// ExponentialBackoff waits the Initial duration after the first attempt and doubles it after each further attempt,
+// until Max is reached. A zero Max does not limit the delay.
+type ExponentialBackoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Next returns the doubled delay of the previous attempt.
+func (b ExponentialBackoff) Next(attempt int) time.Duration {
+	delay := b.Initial
+
+	for i := 1; i < attempt; i++ {
+		if delay > math.MaxInt64/2 || (b.Max > 0 && delay >= b.Max) {
+			break
+		}
+
+		delay *= 2
+	}
+
+	if b.Max > 0 && delay > b.Max {
+		return b.Max
+	}
+
+	return delay
+}
+
+// JitteredBackoff randomizes the delay of the given strategy into the range of its half up to its full value, so that
+// many clients, which failed at the same time, e.g. after a network outage, do not retry at the same time again.
+type JitteredBackoff struct {
+	// Strategy calculates the delay to randomize. Nil is treated as the exponential backoff of the
+	// DefaultRetryPolicy, starting at 500ms up to 30s.
+	Strategy BackoffStrategy
+}
+
+// Next returns the randomized delay of the strategy.
+func (b JitteredBackoff) Next(attempt int) time.Duration {
+	strategy := b.Strategy
+	if strategy == nil {
+		const (
+			defaultBackoff    = 500 * time.Millisecond
+			defaultMaxBackoff = 30 * time.Second
+		)
+
+		strategy = ExponentialBackoff{Initial: defaultBackoff, Max: defaultMaxBackoff}
+	}
+
+	delay := strategy.Next(attempt)
+	if delay <= 1 {
+		return delay
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(delay-half))) //nolint:gosec
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff ExponentialBackoff
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", backoff: ExponentialBackoff{Initial: time.Second}, attempt: 1, want: time.Second},
+		{name: "doubled", backoff: ExponentialBackoff{Initial: time.Second}, attempt: 3, want: 4 * time.Second},
+		{name: "limited", backoff: ExponentialBackoff{Initial: time.Second, Max: 3 * time.Second}, attempt: 3,
+			want: 3 * time.Second},
+		{name: "overflow", backoff: ExponentialBackoff{Initial: math.MaxInt64/2 + 1}, attempt: 2,
+			want: math.MaxInt64/2 + 1},
+		{name: "zero", backoff: ExponentialBackoff{}, attempt: 5, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.backoff.Next(tt.attempt); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestJitteredBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy BackoffStrategy
+		attempt  int
+		delay    time.Duration
+	}{
+		{name: "zero", strategy: ConstantBackoff(0), attempt: 1, delay: 0},
+		{name: "nanosecond", strategy: ConstantBackoff(1), attempt: 1, delay: 1},
+		{name: "second", strategy: ConstantBackoff(time.Second), attempt: 1, delay: time.Second},
+		{name: "nil strategy", attempt: 2, delay: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff := JitteredBackoff{Strategy: tt.strategy}
+
+			for i := 0; i < 100; i++ {
+				if got := backoff.Next(tt.attempt); got < tt.delay/2 || got > tt.delay {
+					t.Fatalf("expected a delay between %v and %v, got %v", tt.delay/2, tt.delay, got)
+				}
+			}
+		})
+	}
+}
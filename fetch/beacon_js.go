@@ -0,0 +1,59 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import "syscall/js"
+
+// SendBeacon queues a POST request with the given body using navigator.sendBeacon, which is delivered by the
+// browser even if the page is unloaded in the meantime, e.g. for analytics. The response is not available. If
+// sendBeacon is not supported, a fetch with the keepalive flag is issued instead. It returns false, if the browser
+// refused to queue the beacon, e.g. because the body is too large (usually about 64KiB).
+func SendBeacon(url string, body []byte) bool {
+	var data js.Value
+
+	if len(body) > 0 {
+		data = js.Global().Get("Uint8Array").New(len(body))
+		js.CopyBytesToJS(data, body)
+	}
+
+	navigator := js.Global().Get("navigator")
+	if !navigator.IsUndefined() && navigator.Get("sendBeacon").Truthy() {
+		if data.IsUndefined() {
+			return navigator.Call("sendBeacon", url).Bool()
+		}
+
+		return navigator.Call("sendBeacon", url, data).Bool()
+	}
+
+	if js.Global().Get("fetch").IsUndefined() {
+		return false
+	}
+
+	init := js.Global().Get("Object").New()
+	init.Set("method", "POST")
+	init.Set("keepalive", true)
+
+	if !data.IsUndefined() {
+		init.Set("body", data)
+	}
+
+	// nobody waits for the result, so a rejection must not be reported as unhandled
+	js.Global().Call("fetch", url, init).Call("catch", js.Global().Get("Function").New())
+
+	return true
+}
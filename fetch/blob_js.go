@@ -0,0 +1,109 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"syscall/js"
+)
+
+// AsObjectURL is a middleware for an async http response, which reads the body into a browser Blob with the
+// Content-Type of the response and creates an object url for it, e.g. to offer a download or to display it. The
+// callback must invoke revoke, when the url is not required anymore, otherwise the Blob is never released.
+func AsObjectURL(f func(url string, revoke func(), err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f("", nil, err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f("", nil, err)
+
+			return
+		}
+
+		url := createObjectURL(newBlob(buf, res.Header.Get("Content-Type")))
+
+		f(url, func() { revokeObjectURL(url) }, nil)
+	}
+}
+
+// AsBlob is a middleware for an async http response, which provides the body as browser Blob, e.g. to store it
+// in the IndexedDB or to create an object url. If the response has been fetched by the FetchTransport, the Blob is
+// obtained from the fetch response directly, without copying the body into the Go heap, especially together with
+// WithResponseType("blob"). Otherwise, the body is read and copied into a new Blob.
+func AsBlob(f func(blob js.Value, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(js.Undefined(), err)
+
+			return
+		}
+
+		body, counted := res.Body, false
+		if counting, ok := body.(*countingBody); ok {
+			body, counted = counting.ReadCloser, true
+		}
+
+		if body, ok := body.(*arrayBufferBody); ok {
+			if blob, ok, err := body.blob(); ok {
+				if counted && err == nil {
+					transfer.bytes.Add(int64(blob.Get("size").Int()))
+				}
+
+				f(blob, err)
+
+				return
+			}
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(js.Undefined(), err)
+
+			return
+		}
+
+		f(newBlob(buf, res.Header.Get("Content-Type")), nil)
+	}
+}
+
+// newBlob copies the data into a new browser Blob of the given type.
+func newBlob(data []byte, contentType string) js.Value {
+	arr := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(arr, data)
+
+	options := js.Global().Get("Object").New()
+	options.Set("type", contentType)
+
+	return js.Global().Get("Blob").New([]interface{}{arr}, options)
+}
+
+// createObjectURL invokes URL.createObjectURL for the blob.
+func createObjectURL(blob js.Value) string {
+	return js.Global().Get("URL").Call("createObjectURL", blob).String()
+}
+
+// revokeObjectURL invokes URL.revokeObjectURL.
+func revokeObjectURL(url string) {
+	js.Global().Get("URL").Call("revokeObjectURL", url)
+}
@@ -0,0 +1,45 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetSync performs a http GET and blocks until the response has been received. The body has already been read into
+// memory, like for RequestChan.
+//
+// WARNING: never call it from the UI or DOM Thread, e.g. from a js event handler, because that deadlocks the
+// entire wasm process. It is only safe to use from a goroutine or in a worker context.
+func GetSync(url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return RequestSync(DefaultClient(), req)
+}
+
+// RequestSync performs the request like Request and blocks until the response has been received. The body has
+// already been read into memory, like for RequestChan.
+//
+// WARNING: never call it from the UI or DOM Thread, e.g. from a js event handler, because that deadlocks the
+// entire wasm process. It is only safe to use from a goroutine or in a worker context.
+func RequestSync(client *http.Client, req *http.Request) (*http.Response, error) {
+	result := <-RequestChan(client, req)
+
+	return result.Res, result.Err
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all requests pass.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all requests with ErrCircuitOpen, until the cool-down has elapsed.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial request pass, which decides whether the circuit is closed or opened again.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures a BreakerClient.
+type BreakerConfig struct {
+	// Client is the underlying client. Nil is treated as the DefaultClient.
+	Client *http.Client
+	// FailureThreshold is the amount of consecutive failures, which opens the circuit. Defaults to 5.
+	FailureThreshold int
+	// CoolDown is the duration of the open state, before a trial request is allowed. Defaults to 30 seconds.
+	CoolDown time.Duration
+	// IsFailure decides, if the result counts as a failure. Defaults to any error and any status code of 500 and
+	// above. Requests cancelled by their context are never counted.
+	IsFailure func(res *http.Response, err error) bool
+}
+
+// A Breaker is the circuit breaker of a BreakerClient, which provides its current state, e.g. to display it.
+type Breaker struct {
+	cfg      BreakerConfig
+	mutex    sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// BreakerClient returns a copy of the configured client, which stops sending requests after the configured amount of
+// consecutive failures and fails fast with ErrCircuitOpen instead. After the cool-down, a single trial request is
+// sent and its success closes the circuit again.
+func BreakerClient(cfg BreakerConfig) (*http.Client, *Breaker) {
+	const (
+		defaultThreshold = 5
+		defaultCoolDown  = 30 * time.Second
+	)
+
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultThreshold
+	}
+
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = defaultCoolDown
+	}
+
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(res *http.Response, err error) bool {
+			return err != nil || res.StatusCode >= http.StatusInternalServerError
+		}
+	}
+
+	breaker := &Breaker{cfg: cfg}
+
+	client := wrapTransport(cfg.Client, func(transport http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if !breaker.allow() {
+				return nil, ErrCircuitOpen
+			}
+
+			res, err := transport.RoundTrip(req)
+			if req.Context().Err() != nil {
+				breaker.record(false, false)
+			} else {
+				breaker.record(cfg.IsFailure(res, err), true)
+			}
+
+			return res, err
+		})
+	})
+
+	return client, breaker
+}
+
+// State returns the current state of the circuit. An open circuit, whose cool-down has elapsed, is reported as
+// half-open.
+func (b *Breaker) State() BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cfg.CoolDown {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// allow decides if a request may pass and starts the trial request of the half-open state.
+func (b *Breaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cfg.CoolDown {
+			return false
+		}
+
+		b.state = BreakerHalfOpen
+
+		return true
+	default:
+		// the trial request is still in flight
+		return false
+	}
+}
+
+// record updates the state by the result of a request. A result, which is not counted, e.g. due to cancellation,
+// only ends a trial, so that the next request becomes the new trial.
+func (b *Breaker) record(failed, counted bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch {
+	case b.state == BreakerHalfOpen && !counted:
+		b.state = BreakerOpen
+	case b.state == BreakerHalfOpen && failed:
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	case b.state == BreakerHalfOpen:
+		b.state = BreakerClosed
+		b.failures = 0
+	case !counted:
+	case failed:
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold && b.state == BreakerClosed {
+			b.state = BreakerOpen
+			b.openedAt = time.Now()
+		}
+	default:
+		b.failures = 0
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryCircuitOpen(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		status       int
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "network error opens the circuit", err: io.ErrUnexpectedEOF, wantAttempts: 2, wantErr: ErrCircuitOpen},
+		{name: "server error opens the circuit", status: http.StatusServiceUnavailable, wantAttempts: 2,
+			wantErr: ErrCircuitOpen},
+		{name: "success", status: http.StatusOK, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+
+			client, breaker := BreakerClient(BreakerConfig{
+				Client: NewMockClient(func(req *http.Request) (*http.Response, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+
+					return MockResponse(tt.status, ""), nil
+				}),
+				FailureThreshold: 1,
+				CoolDown:         time.Hour,
+			})
+
+			client = HookedClient(client, Hooks{OnStart: func(req *http.Request) { attempts++ }})
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			policy := DefaultRetryPolicy()
+			policy.Backoff = nil
+
+			done := make(chan error, 1)
+
+			RetryWithPolicy(client, req, policy, func(res *http.Response, err error) {
+				done <- err
+			})
+
+			select {
+			case err = <-done:
+			case <-time.After(time.Second):
+				t.Fatal("callback not invoked")
+			}
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Fatalf("expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+
+			if want := tt.wantErr != nil; (breaker.State() == BreakerOpen) != want {
+				t.Fatalf("unexpected breaker state %v", breaker.State())
+			}
+		})
+	}
+}
+
+func TestBreakerState(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		coolDown time.Duration
+		want     BreakerState
+	}{
+		{name: "below threshold", failures: 1, coolDown: time.Hour, want: BreakerClosed},
+		{name: "threshold reached", failures: 2, coolDown: time.Hour, want: BreakerOpen},
+		{name: "cool-down elapsed", failures: 2, coolDown: time.Nanosecond, want: BreakerHalfOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, breaker := BreakerClient(BreakerConfig{
+				Client: NewMockClient(func(req *http.Request) (*http.Response, error) {
+					return MockResponse(http.StatusInternalServerError, ""), nil
+				}),
+				FailureThreshold: 2,
+				CoolDown:         tt.coolDown,
+			})
+
+			for i := 0; i < tt.failures; i++ {
+				req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := RequestSync(client, req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+
+			if got := breaker.State(); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
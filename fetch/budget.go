@@ -0,0 +1,74 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// transfer counts the bytes of all response bodies read, see BytesTransferred.
+var transfer struct { //nolint:gochecknoglobals
+	bytes  atomic.Int64
+	budget atomic.Int64
+}
+
+// BytesTransferred returns the amount of bytes read from the bodies of all responses of Request and the functions
+// built upon it, since the start or the last ResetBytesTransferred. Only the bytes actually read are counted, thus an
+// unread or partially read body contributes less than its Content-Length and a compressed body is counted with its
+// decompressed size, as seen by the transport.
+func BytesTransferred() int64 {
+	return transfer.bytes.Load()
+}
+
+// ResetBytesTransferred resets the counter of BytesTransferred to zero, e.g. to track a budget per view.
+func ResetBytesTransferred() {
+	transfer.bytes.Store(0)
+}
+
+// SetByteBudget limits the BytesTransferred. After the budget has been reached, further requests fail with
+// ErrBudgetExceeded, without being sent. Requests in flight are not interrupted, so the budget may be exceeded by
+// their bodies. A max of zero or less removes the limit, which is the default.
+func SetByteBudget(max int64) {
+	transfer.budget.Store(max)
+}
+
+// checkByteBudget returns ErrBudgetExceeded, if the budget has been reached.
+func checkByteBudget() error {
+	budget := transfer.budget.Load()
+	if budget <= 0 {
+		return nil
+	}
+
+	if n := transfer.bytes.Load(); n >= budget {
+		return fmt.Errorf("%d bytes transferred with a budget of %d: %w", n, budget, ErrBudgetExceeded)
+	}
+
+	return nil
+}
+
+// countingBody adds all bytes read to the BytesTransferred.
+type countingBody struct {
+	io.ReadCloser
+}
+
+// Read delegates to the body and counts the bytes read.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	transfer.bytes.Add(int64(n))
+
+	return n, err //nolint:wrapcheck
+}
@@ -0,0 +1,163 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ConditionalCache is an in-memory cache of GET responses, which provide an ETag or Last-Modified header. It is
+// bounded to a maximum amount of entries and evicts the least recently used one. See also CachingClient.
+type ConditionalCache struct {
+	mutex   sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a buffered response.
+type cacheEntry struct {
+	key  string
+	res  *http.Response
+	body []byte
+	// expires is the end of the freshness, see RespectCacheControl.
+	expires time.Time
+}
+
+// NewConditionalCache creates a new empty cache with at most the given amount of entries. A size of less than 1 is
+// treated as 1.
+func NewConditionalCache(size int) *ConditionalCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &ConditionalCache{size: size, lru: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Len returns the amount of cached responses.
+func (c *ConditionalCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lru.Len()
+}
+
+// Clear removes all entries.
+func (c *ConditionalCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lru.Init()
+	c.entries = map[string]*list.Element{}
+}
+
+// get returns the entry of the key and marks it as recently used.
+func (c *ConditionalCache) get(key string) *cacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return elem.Value.(*cacheEntry) //nolint:forcetypeassert
+}
+
+// put inserts or replaces the entry and evicts the least recently used ones.
+func (c *ConditionalCache) put(entry *cacheEntry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[entry.key]; ok {
+		c.lru.Remove(elem)
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key) //nolint:forcetypeassert
+	}
+}
+
+// CachingClient returns a copy of the given client, which uses the cache for GET requests. It remembers the ETag
+// and Last-Modified headers of each url and sends If-None-Match and If-Modified-Since on subsequent requests. A
+// 304 Not Modified is replaced transparently by a copy of the cached response. Requests which already define
+// conditional headers are passed through. A nil client is treated as the DefaultClient.
+//
+// Note, that the browser usually has its own http cache, which may already answer conditional requests itself.
+func CachingClient(client *http.Client, cache *ConditionalCache) *http.Client {
+	return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return cache.roundTrip(transport, req)
+		})
+	})
+}
+
+// roundTrip implements the conditional request.
+func (c *ConditionalCache) roundTrip(transport http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" ||
+		req.Header.Get("If-Modified-Since") != "" {
+		return transport.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry := c.get(key)
+
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if etag := entry.res.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		if lastModified := entry.res.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		_ = Drain(res)
+
+		return cloneResponse(entry.res, entry.body, req), nil
+	}
+
+	if res.StatusCode != http.StatusOK || (res.Header.Get("ETag") == "" && res.Header.Get("Last-Modified") == "") {
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(&cacheEntry{key: key, res: cloneResponse(res, nil, nil), body: body})
+
+	return cloneResponse(res, body, req), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestConditionalCacheSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		urls []string
+		want int
+	}{
+		{name: "zero is treated as one", size: 0, urls: []string{"/a"}, want: 1},
+		{name: "negative is treated as one", size: -1, urls: []string{"/a", "/b"}, want: 1},
+		{name: "evicts the least recently used", size: 2, urls: []string{"/a", "/b", "/c"}, want: 2},
+		{name: "replaces the same url", size: 2, urls: []string{"/a", "/a"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewConditionalCache(tt.size)
+			client := CachingClient(NewMockClient(func(req *http.Request) (*http.Response, error) {
+				res := MockResponse(http.StatusOK, "hello")
+				res.Header.Set("ETag", `"1"`)
+
+				return res, nil
+			}), cache)
+
+			for _, path := range tt.urls {
+				req, err := http.NewRequest(http.MethodGet, "http://localhost"+path, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if res := <-RequestChan(client, req); res.Err != nil {
+					t.Fatal(res.Err)
+				}
+			}
+
+			if got := cache.Len(); got != tt.want {
+				t.Fatalf("expected %d entries, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RespectCacheControl returns a copy of the given client, which keeps successful GET responses in memory as long as
+// they are fresh according to their Cache-Control max-age directive or their Expires header, and answers
+// subsequent requests for the same url without using the network. Responses with no-store are never kept. A
+// response with no-cache, or one which became stale, is revalidated using its ETag or Last-Modified header and a
+// 304 Not Modified renews the freshness of the kept copy. At most size responses are kept, evicting the least
+// recently used one. A nil client is treated as the DefaultClient.
+//
+// Unlike CachingClient, which always asks the server, this client trusts the freshness headers.
+func RespectCacheControl(client *http.Client, size int) *http.Client {
+	cache := NewConditionalCache(size)
+
+	return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return cache.freshRoundTrip(transport, req)
+		})
+	})
+}
+
+// freshRoundTrip implements RespectCacheControl.
+func (c *ConditionalCache) freshRoundTrip(transport http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return transport.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry := c.get(key)
+
+	if entry != nil && time.Now().Before(entry.expires) {
+		return cloneResponse(entry.res, entry.body, req), nil
+	}
+
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if etag := entry.res.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+
+		if lastModified := entry.res.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		_ = Drain(res)
+
+		expires, _ := freshness(res.Header)
+		c.put(&cacheEntry{key: key, res: entry.res, body: entry.body, expires: expires})
+
+		return cloneResponse(entry.res, entry.body, req), nil
+	}
+
+	expires, cacheable := freshness(res.Header)
+	if !isSuccess(res.StatusCode) || res.StatusCode == http.StatusPartialContent || !cacheable {
+		return res, nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(&cacheEntry{key: key, res: cloneResponse(res, nil, nil), body: body, expires: expires})
+
+	return cloneResponse(res, body, req), nil
+}
+
+// freshness returns the expiry of a response with the given headers and if it may be kept at all. A no-cache
+// response is kept, but expires immediately.
+func freshness(header http.Header) (expires time.Time, cacheable bool) {
+	now := time.Now()
+	directives := map[string]string{}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			key, arg := directive, ""
+			if eq := strings.Index(directive, "="); eq >= 0 {
+				key, arg = directive[:eq], directive[eq+1:]
+			}
+
+			directives[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+
+	if _, ok := directives["no-store"]; ok {
+		return time.Time{}, false
+	}
+
+	if _, ok := directives["no-cache"]; ok {
+		return now, true
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return now, true
+		}
+
+		if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+			seconds -= age
+		}
+
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+
+	if value := header.Get("Expires"); value != "" {
+		// an invalid date, like 0, means already expired
+		date, err := http.ParseTime(value)
+		if err != nil {
+			return now, true
+		}
+
+		return date, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,116 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"syscall/js"
+)
+
+// CacheFirst performs a GET request for the url, which is answered from the browser Cache Storage with the given
+// name, if it contains a matching response. Otherwise, the response is fetched from the network and a successful
+// response (2xx) is put into the cache, before it is passed to the callback. If the Cache Storage is not available,
+// e.g. within an insecure context, the network is used without caching. See also Request.
+func CacheFirst(cacheName, url string, f func(res *http.Response, err error)) {
+	requestCacheStorage(cacheName, url, false, f)
+}
+
+// NetworkFirst performs a GET request for the url like CacheFirst, but uses the network first and puts a successful
+// response into the cache. Only if the request fails, e.g. because the browser is offline, the matching response of
+// the Cache Storage is passed to the callback. If there is none, the callback receives the error of the request.
+func NetworkFirst(cacheName, url string, f func(res *http.Response, err error)) {
+	requestCacheStorage(cacheName, url, true, f)
+}
+
+// requestCacheStorage performs the request using a cacheStorageTransport.
+func requestCacheStorage(cacheName, url string, networkFirst bool, f func(res *http.Response, err error)) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	client := &http.Client{Transport: &cacheStorageTransport{name: cacheName, networkFirst: networkFirst}}
+	Request(client, req, f)
+}
+
+// cacheStorageTransport is a http.RoundTripper like the FetchTransport, which keeps the js responses of successful
+// requests within a cache of the Cache Storage.
+type cacheStorageTransport struct {
+	name         string
+	networkFirst bool
+}
+
+// supportsFetchOptions returns true, because the requests are performed like by the FetchTransport.
+func (t *cacheStorageTransport) supportsFetchOptions() bool {
+	return true
+}
+
+// RoundTrip answers the request by the cache or the network, according to the strategy.
+func (t *cacheStorageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	storage := js.Global().Get("caches")
+	if storage.IsUndefined() {
+		return (&FetchTransport{}).RoundTrip(req)
+	}
+
+	cache, err := await(storage.Call("open", t.name))
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.networkFirst {
+		if res := matchCache(req, cache); res != nil {
+			return res, nil
+		}
+	}
+
+	response, done, err := jsFetch(req)
+	if err != nil {
+		if t.networkFirst && req.Context().Err() == nil {
+			if res := matchCache(req, cache); res != nil {
+				return res, nil
+			}
+		}
+
+		return nil, err
+	}
+
+	if response.Get("ok").Bool() {
+		// a failure to cache the response must not fail the request
+		_, _ = await(cache.Call("put", req.URL.String(), response.Call("clone")))
+	}
+
+	return newResponse(req, response, done)
+}
+
+// matchCache returns the cached response for the request or nil.
+func matchCache(req *http.Request, cache js.Value) *http.Response {
+	response, err := await(cache.Call("match", req.URL.String()))
+	if err != nil || response.IsUndefined() {
+		return nil
+	}
+
+	res, err := newResponse(req, response, make(chan struct{}))
+	if err != nil {
+		return nil
+	}
+
+	return res
+}
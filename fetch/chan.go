@@ -0,0 +1,195 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Result is the outcome of a request, as delivered by the channel based API. If Err is nil, Res contains a response
+// whose body has already been read into memory, so it stays valid after the request has been completed.
+type Result struct {
+	Res *http.Response
+	Err error
+}
+
+// GetChan performs a http GET like Get but delivers the result through the returned channel. Exactly one Result is
+// sent and the channel is closed afterwards. The channel is buffered, so the request does not leak if the result
+// is never received.
+func GetChan(url string) <-chan Result {
+	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	if err != nil {
+		c := make(chan Result, 1)
+		c <- Result{Err: err}
+		close(c)
+
+		return c
+	}
+
+	return RequestChan(DefaultClient(), req)
+}
+
+// RequestChan is like Request but delivers the result through the returned channel. See also GetChan.
+func RequestChan(client *http.Client, request *http.Request) <-chan Result {
+	c := make(chan Result, 1)
+
+	Request(client, request, func(res *http.Response, err error) {
+		defer close(c)
+
+		if err == nil {
+			err = bufferBody(res)
+		}
+
+		if err != nil {
+			c <- Result{Err: err}
+
+			return
+		}
+
+		c <- Result{Res: res}
+	})
+
+	return c
+}
+
+// WaitAll performs all requests concurrently using Request and invokes the callback once, after each request has
+// been completed. The results have the same order as the requests and each failure is reported individually by
+// Result.Err. Like for RequestChan, the bodies have already been read into memory.
+func WaitAll(reqs []*http.Request, client *http.Client, f func(results []Result)) {
+	if len(reqs) == 0 {
+		f(nil)
+
+		return
+	}
+
+	var mutex sync.Mutex
+
+	results := make([]Result, len(reqs))
+	pending := len(reqs)
+
+	for i, req := range reqs {
+		i := i
+
+		Request(client, req, func(res *http.Response, err error) {
+			if err == nil {
+				err = bufferBody(res)
+			}
+
+			if err != nil {
+				res = nil
+			}
+
+			mutex.Lock()
+			results[i] = Result{Res: res, Err: err}
+			pending--
+			done := pending == 0
+			mutex.Unlock()
+
+			if done {
+				f(results)
+			}
+		})
+	}
+}
+
+// bufferBody reads the entire body into memory and replaces it, so that it can be used and closed independently of
+// the life cycle of the request.
+func bufferBody(res *http.Response) error {
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(buf))
+
+	return nil
+}
+
+// streamChunkSize is the maximum size of a chunk sent by GetStream.
+const streamChunkSize = 32 * 1024
+
+// GetStream performs a http GET like Get and sends the body in chunks to the data channel, as they arrive from the
+// ReadableStream of the response. The data channel is closed at the end of the body or at the first error, which is
+// sent to the error channel afterwards, e.g. a *HTTPError for a status code other than 2xx. The error channel is
+// buffered and closed after the data channel. Each chunk is a copy and stays valid. The request is only completed,
+// if the data channel is received until it is closed, use GetStreamContext to stop receiving early.
+func GetStream(url string) (<-chan []byte, <-chan error) {
+	return GetStreamContext(context.Background(), url)
+}
+
+// GetStreamContext is like GetStream but uses the given context for the request. Cancelling it aborts the request,
+// even while a chunk is waiting to be received, so that the consumer may stop receiving at any time. In that case,
+// the data channel is closed and the error of the context is sent to the error channel.
+func GetStreamContext(ctx context.Context, url string) (<-chan []byte, <-chan error) {
+	data := make(chan []byte)
+	errs := make(chan error, 1)
+
+	GetContext(ctx, url, func(res *http.Response, err error) {
+		defer close(errs)
+
+		err = streamBody(ctx, res, err, data)
+		close(data)
+
+		if err != nil {
+			errs <- err
+		}
+	})
+
+	return data, errs
+}
+
+// streamBody sends the body of a successful response in chunks to the channel, until the context is done.
+func streamBody(ctx context.Context, res *http.Response, err error, data chan<- []byte) error {
+	if err != nil {
+		return err
+	}
+
+	if !isSuccess(res.StatusCode) {
+		return newHTTPError(res)
+	}
+
+	buf := make([]byte, streamChunkSize)
+
+	for {
+		n, err := res.Body.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+
+			select {
+			case data <- chunk:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetStreamContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		cancel     bool
+		want       string
+		wantErr    error
+		wantStatus int
+	}{
+		{name: "complete body", status: http.StatusOK, body: "hello", want: "hello"},
+		{name: "status error", status: http.StatusNotFound, body: "missing", wantStatus: http.StatusNotFound},
+		{name: "cancelled while sending", status: http.StatusOK, cancel: true, want: "data",
+			wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closed := make(chan struct{})
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer close(closed)
+
+				w.WriteHeader(tt.status)
+
+				if !tt.cancel {
+					_, _ = w.Write([]byte(tt.body))
+
+					return
+				}
+
+				// an endless body, which only ends when the client aborts the request
+				for r.Context().Err() == nil {
+					if _, err := w.Write([]byte("data")); err != nil {
+						return
+					}
+
+					w.(http.Flusher).Flush()
+				}
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			data, errs := GetStreamContext(ctx, server.URL)
+
+			var got strings.Builder
+
+			for chunk := range data {
+				got.Write(chunk)
+
+				if tt.cancel {
+					// stop receiving, while the producer waits to send the next chunk
+					cancel()
+				}
+			}
+
+			var err error
+			select {
+			case err = <-errs:
+			case <-time.After(time.Second):
+				t.Fatal("error channel not closed")
+			}
+
+			// a cancelled stream may still deliver some chunks, which were sent concurrently
+			if got.String() != tt.want && !(tt.cancel && strings.HasPrefix(got.String(), tt.want)) {
+				t.Fatalf("expected %q, got %q", tt.want, got.String())
+			}
+
+			var httpErr *HTTPError
+
+			switch {
+			case tt.wantStatus != 0:
+				if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.wantStatus {
+					t.Fatalf("expected a *HTTPError with status %d, got %v", tt.wantStatus, err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.cancel {
+				select {
+				case <-closed:
+				case <-time.After(time.Second):
+					t.Fatal("request not aborted")
+				}
+			}
+		})
+	}
+}
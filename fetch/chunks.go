@@ -0,0 +1,122 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// statusResumeIncomplete is used by resumable upload protocols to acknowledge a chunk, which is not the last one.
+const statusResumeIncomplete = 308
+
+// UploadChunks reads the source in pieces of chunkSize bytes and sends each of them with a http PUT and a
+// Content-Range header to the url, one after another, so that only a single chunk is kept in memory. The total size
+// is only announced by the last chunk, e.g. "bytes 0-99/*" followed by "bytes 100-149/150". Each chunk must be
+// acknowledged with a 2xx or a 308 Resume Incomplete, otherwise the upload stops and the callback receives an
+// *HTTPError. The callback receives the response of the last chunk or the first error, e.g. of reading the source.
+func UploadChunks(url string, r io.Reader, chunkSize int64, f func(res *http.Response, err error)) {
+	UploadChunksProgress(url, r, chunkSize, nil, f)
+}
+
+// UploadChunksProgress is like UploadChunks but reports the amount of bytes, which have been acknowledged, to
+// onChunk after each chunk. onChunk may be nil.
+func UploadChunksProgress(url string, r io.Reader, chunkSize int64, onChunk func(sent int64),
+	f func(res *http.Response, err error)) {
+	if chunkSize <= 0 {
+		f(nil, fmt.Errorf("invalid chunk size %d: %w", chunkSize, ErrInvalidOption))
+
+		return
+	}
+
+	// the source may block, so it is never read from the calling goroutine
+	go func() {
+		defer GlobalPanicHandler()
+
+		uploadChunk(url, bufio.NewReader(r), chunkSize, 0, onChunk, f)
+	}()
+}
+
+// uploadChunk reads and sends the chunk at the given offset and continues with the next one.
+func uploadChunk(url string, r *bufio.Reader, chunkSize, offset int64, onChunk func(sent int64),
+	f func(res *http.Response, err error)) {
+	buf := make([]byte, chunkSize)
+
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		f(nil, err)
+
+		return
+	}
+
+	_, peekErr := r.Peek(1)
+	last := peekErr != nil
+
+	if peekErr != nil && peekErr != io.EOF {
+		f(nil, peekErr)
+
+		return
+	}
+
+	contentRange := fmt.Sprintf("bytes %d-%d/*", offset, offset+int64(n)-1)
+
+	switch {
+	case n == 0:
+		contentRange = fmt.Sprintf("bytes */%d", offset)
+	case last:
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(n)-1, offset+int64(n))
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, url, bytes.NewReader(buf[:n]))
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	req.Header.Set("Content-Range", contentRange)
+
+	Request(DefaultClient(), req, func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		if !isSuccess(res.StatusCode) && (last || res.StatusCode != statusResumeIncomplete) {
+			f(nil, newHTTPError(res))
+
+			return
+		}
+
+		if onChunk != nil {
+			onChunk(offset + int64(n))
+		}
+
+		if last {
+			f(res, nil)
+
+			return
+		}
+
+		_ = Drain(res)
+
+		uploadChunk(url, r, chunkSize, offset+int64(n), onChunk, f)
+	})
+}
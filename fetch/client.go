@@ -0,0 +1,208 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ClientConfig contains the defaults, which are applied to all requests of a Client.
+type ClientConfig struct {
+	// HTTPClient performs the requests. If nil, the DefaultClient is used.
+	HTTPClient *http.Client
+	// Headers are set for each request. A header of an individual request overrides the default.
+	Headers map[string]string
+	// BaseURL is used to resolve relative urls, e.g. https://api.example.com/v1 and /users resolve to
+	// https://api.example.com/v1/users. Absolute urls are used as is.
+	BaseURL string
+	// Hooks observe each round trip of the client, see also HookedClient.
+	Hooks *Hooks
+}
+
+// Client provides the same convenience functions as the package, but applies its configured defaults to each request.
+type Client struct {
+	cfg    ClientConfig
+	client *http.Client
+}
+
+// NewClient creates a new Client from the given configuration.
+func NewClient(cfg ClientConfig) *Client {
+	c := &Client{cfg: cfg, client: cfg.HTTPClient}
+	if cfg.Hooks != nil {
+		c.client = HookedClient(c.client, *cfg.Hooks)
+	}
+
+	return c
+}
+
+// Get performs a http GET. See also Get.
+func (c *Client) Get(url string, f func(res *http.Response, err error)) {
+	c.Do(url, f)
+}
+
+// Post performs a http POST. See also Post.
+func (c *Client) Post(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	c.send(http.MethodPost, url, contentType, body, f)
+}
+
+// Put performs a http PUT. See also Put.
+func (c *Client) Put(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	c.send(http.MethodPut, url, contentType, body, f)
+}
+
+// Patch performs a http PATCH. See also Patch.
+func (c *Client) Patch(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	c.send(http.MethodPatch, url, contentType, body, f)
+}
+
+// Delete performs a http DELETE. See also Delete.
+func (c *Client) Delete(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	c.send(http.MethodDelete, url, contentType, body, f)
+}
+
+// Do creates a request from the given url and options and performs it. The defaults are applied before the
+// given options. See also Do.
+func (c *Client) Do(url string, f func(res *http.Response, err error), opts ...Option) {
+	u, err := c.resolve(url)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	req, err := NewRequest(u.String(), append([]Option{WithHeaders(c.cfg.Headers)}, opts...)...)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	Request(c.httpClient(), req, f)
+}
+
+// Request applies the default headers, which are not already defined by the request, and performs it. A relative
+// url of the request is resolved against the base url. The defaults are applied to a copy, so that the given request
+// stays unchanged and can be performed again. See also Request.
+func (c *Client) Request(request *http.Request, f func(res *http.Response, err error)) {
+	request = request.Clone(request.Context())
+
+	if !request.URL.IsAbs() {
+		u, err := c.resolve(request.URL.String())
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		request.URL = u
+		request.Host = u.Host
+	}
+
+	for key, value := range c.cfg.Headers {
+		if _, ok := request.Header[http.CanonicalHeaderKey(key)]; !ok {
+			request.Header.Set(key, value)
+		}
+	}
+
+	Request(c.httpClient(), request, f)
+}
+
+// send performs a request with an optional body.
+func (c *Client) send(method, url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	opts := []Option{WithMethod(method), WithBody(body)}
+	if contentType != "" {
+		opts = append(opts, WithHeader("Content-Type", contentType))
+	}
+
+	c.Do(url, f, opts...)
+}
+
+// resolve joins the relative url with the base url. In contrast to url.ResolveReference, a leading slash does not
+// replace the path of the base url. The paths are joined in their escaped form, so that an escaped slash is kept.
+func (c *Client) resolve(rawURL string) (*url.URL, error) {
+	ref, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.IsAbs() || c.cfg.BaseURL == "" {
+		return ref, nil
+	}
+
+	base, err := url.Parse(c.cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := base.EscapedPath()
+	if !strings.HasSuffix(basePath, "/") {
+		basePath += "/"
+	}
+
+	if err := setEscapedPath(base, basePath); err != nil {
+		return nil, err
+	}
+
+	if err := setEscapedPath(ref, strings.TrimLeft(ref.EscapedPath(), "/")); err != nil {
+		return nil, err
+	}
+
+	return base.ResolveReference(ref), nil
+}
+
+// setEscapedPath replaces the path of the url by the given escaped path, keeping Path and RawPath consistent.
+func setEscapedPath(u *url.URL, escaped string) error {
+	path, err := url.PathUnescape(escaped)
+	if err != nil {
+		return err
+	}
+
+	u.Path, u.RawPath = path, escaped
+
+	return nil
+}
+
+// httpClient returns the configured or the default http client.
+func (c *Client) httpClient() *http.Client {
+	if c.client != nil {
+		return c.client
+	}
+
+	return DefaultClient()
+}
+
+// defaultClient is used by the package level functions, see SetDefaultClient.
+var defaultClient = http.DefaultClient //nolint:gochecknoglobals
+
+// SetDefaultClient replaces the client, which is used by Get, Post, Do and all other functions of the package
+// without an explicit client, e.g. to configure an app-wide timeout or transport. Default headers can be applied by
+// a wrapping transport, see RoundTripFunc. A nil client restores the http.DefaultClient. It should only be set once
+// at startup: replacing it concurrently to requests in flight is undefined and clients, which have been derived
+// before, like a BreakerClient, keep the previous one.
+func SetDefaultClient(client *http.Client) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	defaultClient = client
+}
+
+// DefaultClient returns the client set by SetDefaultClient, which is the http.DefaultClient by default.
+func DefaultClient() *http.Client {
+	return defaultClient
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		url  string
+		want string
+	}{
+		{name: "no base", url: "/users", want: "/users"},
+		{name: "leading slash", base: "https://api.example.com/v1", url: "/users",
+			want: "https://api.example.com/v1/users"},
+		{name: "trailing slash", base: "https://api.example.com/v1/", url: "users",
+			want: "https://api.example.com/v1/users"},
+		{name: "both slashes", base: "https://api.example.com/v1/", url: "/users",
+			want: "https://api.example.com/v1/users"},
+		{name: "no slashes", base: "https://api.example.com/v1", url: "users",
+			want: "https://api.example.com/v1/users"},
+		{name: "base without path", base: "https://api.example.com", url: "/users",
+			want: "https://api.example.com/users"},
+		{name: "query", base: "https://api.example.com/v1", url: "/users?page=2",
+			want: "https://api.example.com/v1/users?page=2"},
+		{name: "escaped slash", base: "https://api.example.com/v1", url: "/files/a%2Fb",
+			want: "https://api.example.com/v1/files/a%2Fb"},
+		{name: "escaped base", base: "https://api.example.com/a%2Fb", url: "/files",
+			want: "https://api.example.com/a%2Fb/files"},
+		{name: "absolute", base: "https://api.example.com/v1", url: "https://other.example.com/users",
+			want: "https://other.example.com/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(ClientConfig{BaseURL: tt.base})
+
+			u, err := c.resolve(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := u.String(); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientRequestKeepsRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		header  http.Header
+		wantURL string
+		wantFoo string
+	}{
+		{name: "relative url and default header", url: "/users", header: http.Header{},
+			wantURL: "https://api.example.com/v1/users", wantFoo: "default"},
+		{name: "own header", url: "/users", header: http.Header{"X-Foo": {"own"}},
+			wantURL: "https://api.example.com/v1/users", wantFoo: "own"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(chan *http.Request, 2)
+
+			c := NewClient(ClientConfig{
+				HTTPClient: NewMockClient(func(req *http.Request) (*http.Response, error) {
+					requests <- req
+
+					return MockResponse(http.StatusOK, ""), nil
+				}),
+				Headers: map[string]string{"X-Foo": "default"},
+				BaseURL: "https://api.example.com/v1",
+			})
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header = tt.header
+
+			// performing the same request twice must apply the defaults only once
+			for i := 0; i < 2; i++ {
+				c.Request(req, func(res *http.Response, err error) {
+					if err != nil {
+						t.Error(err)
+					}
+				})
+
+				sent := <-requests
+				if got := sent.URL.String(); got != tt.wantURL {
+					t.Fatalf("expected url %s, got %s", tt.wantURL, got)
+				}
+
+				if got := sent.Header.Get("X-Foo"); got != tt.wantFoo {
+					t.Fatalf("expected X-Foo '%s', got '%s'", tt.wantFoo, got)
+				}
+			}
+
+			if got := req.URL.String(); got != tt.url {
+				t.Fatalf("expected the request to keep its url %s, got %s", tt.url, got)
+			}
+
+			if len(req.Header) != len(tt.header) || req.Header.Get("X-Foo") != tt.header.Get("X-Foo") {
+				t.Fatalf("expected the request to keep its headers, got %v", req.Header)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FromCurl creates a request from a curl command line, as shared for reproducing an issue. Only a common subset is
+// supported: the url (also as --url), -X/--request, -H/--header, -d/--data/--data-raw/--data-binary, -u/--user and
+// the flags -s, -S, -L, -k, -v, -i and --compressed, which are ignored. Like curl, data implies a POST with the
+// Content-Type application/x-www-form-urlencoded, unless defined otherwise, and multiple data are joined by &.
+// Reading data from a file (@file) is not supported. Errors wrap ErrInvalidCurl and name the failing token.
+//
+// The arguments are quoted like in a posix shell, e.g. a single quote within a single quoted argument is escaped by
+// closing the quotes:
+//
+//	curl -d 'it'\''s' https://my.domain/
+func FromCurl(cmd string) (*http.Request, error) {
+	tokens, err := splitCommandLine(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 || tokens[0] != "curl" {
+		return nil, fmt.Errorf("%w: expected 'curl' as first token", ErrInvalidCurl)
+	}
+
+	var (
+		method, rawURL, user string
+		data                 []string
+		headers              [][2]string
+	)
+
+	for i := 1; i < len(tokens); i++ {
+		token := tokens[i]
+
+		arg := func() (string, error) {
+			if i+1 >= len(tokens) {
+				return "", fmt.Errorf("%w: missing argument of '%s'", ErrInvalidCurl, token)
+			}
+
+			i++
+
+			return tokens[i], nil
+		}
+
+		switch token {
+		case "-X", "--request", "-H", "--header", "-d", "--data", "--data-raw", "--data-binary", "--url",
+			"-u", "--user":
+			value, err := arg()
+			if err != nil {
+				return nil, err
+			}
+
+			switch token {
+			case "-X", "--request":
+				method = value
+			case "-H", "--header":
+				colon := strings.Index(value, ":")
+				if colon <= 0 {
+					return nil, fmt.Errorf("%w: malformed header '%s'", ErrInvalidCurl, value)
+				}
+
+				headers = append(headers, [2]string{strings.TrimSpace(value[:colon]), strings.TrimSpace(value[colon+1:])})
+			case "--url":
+				rawURL = value
+			case "-u", "--user":
+				user = value
+			default:
+				if strings.HasPrefix(value, "@") && token != "--data-raw" {
+					return nil, fmt.Errorf("%w: reading data from a file is not supported '%s'", ErrInvalidCurl, value)
+				}
+
+				data = append(data, value)
+			}
+		case "-s", "--silent", "-S", "--show-error", "-L", "--location", "-k", "--insecure", "-v", "--verbose",
+			"-i", "--include", "--compressed":
+		default:
+			if strings.HasPrefix(token, "-") {
+				return nil, fmt.Errorf("%w: unsupported flag '%s'", ErrInvalidCurl, token)
+			}
+
+			if rawURL != "" {
+				return nil, fmt.Errorf("%w: unexpected second url '%s'", ErrInvalidCurl, token)
+			}
+
+			rawURL = token
+		}
+	}
+
+	return newCurlRequest(method, rawURL, user, data, headers)
+}
+
+// newCurlRequest creates the request from the parsed curl arguments.
+func newCurlRequest(method, rawURL, user string, data []string, headers [][2]string) (*http.Request, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("%w: missing url", ErrInvalidCurl)
+	}
+
+	if method == "" {
+		method = http.MethodGet
+		if len(data) > 0 {
+			method = http.MethodPost
+		}
+	}
+
+	var body io.Reader
+	if len(data) > 0 {
+		body = strings.NewReader(strings.Join(data, "&"))
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid url '%s': %v", ErrInvalidCurl, rawURL, err)
+	}
+
+	for _, header := range headers {
+		req.Header.Add(header[0], header[1])
+	}
+
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	if user != "" {
+		name, password, _ := strings.Cut(user, ":")
+		req.SetBasicAuth(name, password)
+	}
+
+	return req, nil
+}
+
+// splitCommandLine splits the command line into its tokens like a posix shell, supporting single and double quotes,
+// backslash escapes and line continuations. Within single quotes, all characters are literal, thus a single quote
+// has to be escaped outside of them. Within double quotes, a backslash only escapes $, `, ", \ and a newline and is
+// kept otherwise.
+func splitCommandLine(cmd string) ([]string, error) {
+	var (
+		tokens  []string
+		current strings.Builder
+		inToken bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			escaped = false
+			// within double quotes, a backslash only escapes the characters, which are special there
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", r) {
+				current.WriteRune('\\')
+			}
+
+			if r != '\n' {
+				current.WriteRune(r)
+				inToken = true
+			}
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("%w: unterminated quote or escape", ErrInvalidCurl)
+	}
+
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
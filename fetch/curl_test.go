@@ -0,0 +1,155 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestFromCurl(t *testing.T) {
+	tests := []struct {
+		name       string
+		cmd        string
+		wantMethod string
+		wantURL    string
+		wantHeader http.Header
+		wantBody   string
+	}{
+		{
+			name:       "get",
+			cmd:        `curl https://my.domain/users`,
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/users",
+		},
+		{
+			name:       "data implies post",
+			cmd:        `curl -d a=1 --data b=2 https://my.domain/users`,
+			wantMethod: http.MethodPost,
+			wantURL:    "https://my.domain/users",
+			wantHeader: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			wantBody:   "a=1&b=2",
+		},
+		{
+			name:       "single quote escape",
+			cmd:        `curl -X PUT -H 'Content-Type: text/plain' -d 'it'\''s' 'https://my.domain/users'`,
+			wantMethod: http.MethodPut,
+			wantURL:    "https://my.domain/users",
+			wantHeader: http.Header{"Content-Type": {"text/plain"}},
+			wantBody:   "it's",
+		},
+		{
+			name:       "single quotes are literal",
+			cmd:        `curl -d 'a\"b\\c' https://my.domain/`,
+			wantMethod: http.MethodPost,
+			wantURL:    "https://my.domain/",
+			wantBody:   `a\"b\\c`,
+		},
+		{
+			name:       "double quotes escape special characters",
+			cmd:        `curl -d "\"\$x\` + "`" + `\\" https://my.domain/`,
+			wantMethod: http.MethodPost,
+			wantURL:    "https://my.domain/",
+			wantBody:   `"$x` + "`" + `\`,
+		},
+		{
+			name:       "double quotes keep other backslashes",
+			cmd:        `curl -d "a\nb\d" https://my.domain/`,
+			wantMethod: http.MethodPost,
+			wantURL:    "https://my.domain/",
+			wantBody:   `a\nb\d`,
+		},
+		{
+			name:       "unquoted backslash escapes",
+			cmd:        `curl -d a\ b\\c https://my.domain/`,
+			wantMethod: http.MethodPost,
+			wantURL:    "https://my.domain/",
+			wantBody:   `a b\c`,
+		},
+		{
+			name:       "line continuation",
+			cmd:        "curl \\\n  -H \"Accept: text/plain\" \\\n  https://my.domain/",
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/",
+			wantHeader: http.Header{"Accept": {"text/plain"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := FromCurl(tt.cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if req.Method != tt.wantMethod {
+				t.Fatalf("expected method %s, got %s", tt.wantMethod, req.Method)
+			}
+
+			if got := req.URL.String(); got != tt.wantURL {
+				t.Fatalf("expected url %s, got %s", tt.wantURL, got)
+			}
+
+			for key := range tt.wantHeader {
+				if got := req.Header.Get(key); got != tt.wantHeader.Get(key) {
+					t.Fatalf("expected header %s '%s', got '%s'", key, tt.wantHeader.Get(key), got)
+				}
+			}
+
+			var body string
+
+			if req.Body != nil {
+				buf, err := io.ReadAll(req.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				body = string(buf)
+			}
+
+			if body != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestFromCurlInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+	}{
+		{name: "empty", cmd: ""},
+		{name: "not curl", cmd: "wget https://my.domain/"},
+		{name: "missing url", cmd: "curl -s"},
+		{name: "second url", cmd: "curl https://my.domain/a https://my.domain/b"},
+		{name: "unsupported flag", cmd: "curl -F a=1 https://my.domain/"},
+		{name: "missing argument", cmd: "curl https://my.domain/ -H"},
+		{name: "malformed header", cmd: "curl -H nocolon https://my.domain/"},
+		{name: "data from file", cmd: "curl -d @body.json https://my.domain/"},
+		{name: "unterminated quote", cmd: `curl "https://my.domain/`},
+		{name: "unterminated escape", cmd: `curl https://my.domain/\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromCurl(tt.cmd); !errors.Is(err, ErrInvalidCurl) {
+				t.Fatalf("expected ErrInvalidCurl, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Debounce returns a function which coalesces rapid invocations: only the fire function of the last invocation is
+// called, after no further invocation happened for the given interval. The fire function is called from its own
+// goroutine, like the request callbacks.
+func Debounce(interval time.Duration) func(fire func()) {
+	var (
+		mutex sync.Mutex
+		timer *time.Timer
+	)
+
+	return func(fire func()) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		timer = time.AfterFunc(interval, func() {
+			defer GlobalPanicHandler()
+
+			fire()
+		})
+	}
+}
+
+// DebouncedGet returns a function which performs a http GET like Get, but debounced by the given interval, e.g.
+// for search-as-you-type. The callbacks of coalesced invocations are never called. If a request is still in flight
+// when the next one is dispatched, it is aborted and its callback receives context.Canceled.
+func DebouncedGet(interval time.Duration) func(url string, f func(res *http.Response, err error)) {
+	debounce := Debounce(interval)
+
+	var (
+		mutex  sync.Mutex
+		cancel = func() {}
+	)
+
+	return func(url string, f func(res *http.Response, err error)) {
+		debounce(func() {
+			ctx, cancelRequest := context.WithCancel(context.Background())
+
+			mutex.Lock()
+			cancel()
+			cancel = cancelRequest
+			mutex.Unlock()
+
+			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+			if err != nil {
+				cancelRequest()
+				f(nil, err)
+
+				return
+			}
+
+			Request(DefaultClient(), req, func(res *http.Response, err error) {
+				defer cancelRequest()
+
+				f(res, err)
+			})
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// A ContentDecoder wraps a body, which has been encoded with a specific Content-Encoding, into its plain form.
+type ContentDecoder func(r io.Reader) (io.ReadCloser, error)
+
+// contentDecoders contains the decoders by their lowercase content coding, see RegisterContentDecoder.
+var contentDecoders = struct { //nolint:gochecknoglobals
+	mutex sync.Mutex
+	byKey map[string]ContentDecoder
+}{byKey: map[string]ContentDecoder{
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": func(r io.Reader) (io.ReadCloser, error) {
+		return zlib.NewReader(r)
+	},
+}}
+
+// RegisterContentDecoder registers or replaces the decoder of a content coding, e.g. for br using a brotli package.
+// By default, gzip and deflate are supported.
+func RegisterContentDecoder(encoding string, decoder ContentDecoder) {
+	contentDecoders.mutex.Lock()
+	defer contentDecoders.mutex.Unlock()
+
+	contentDecoders.byKey[strings.ToLower(encoding)] = decoder
+}
+
+// WithAcceptEncoding sets the Accept-Encoding header to the given content codings, e.g. "gzip, br", and decodes a
+// response, whose Content-Encoding has a registered decoder, before it is passed to the callback. A response with an
+// unknown coding is passed as is. Note, that the browser manages this header and decodes responses itself, so this
+// is mainly useful for other transports and servers, which send encoded bodies regardless.
+func WithAcceptEncoding(encodings string) Option {
+	return func(b *builder) error {
+		b.header.Set("Accept-Encoding", encodings)
+		b.wrap = append(b.wrap, func(client *http.Client, req *http.Request, next callback) callback {
+			return func(res *http.Response, err error) {
+				if err == nil {
+					err = decodeContent(res)
+				}
+
+				if err != nil {
+					next(nil, err)
+
+					return
+				}
+
+				next(res, nil)
+			}
+		})
+
+		return nil
+	}
+}
+
+// decodeContent replaces the body by its decoded form, if there is a decoder for each of its content codings. A
+// response without a body, e.g. of a HEAD request, is left untouched, even if it declares a Content-Encoding.
+func decodeContent(res *http.Response) error {
+	if !hasBody(res) {
+		return nil
+	}
+
+	var codings []string
+
+	for _, value := range res.Header.Values("Content-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			if coding = strings.ToLower(strings.TrimSpace(coding)); coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+
+	if len(codings) == 0 {
+		return nil
+	}
+
+	decoders := lookupDecoders(codings)
+	if decoders == nil {
+		return nil
+	}
+
+	body := &decodedBody{closers: []io.Closer{res.Body}}
+	r := io.Reader(res.Body)
+
+	// the codings are listed in the order of their application
+	for i := len(decoders) - 1; i >= 0; i-- {
+		decoded, err := decoders[i](r)
+		if err != nil {
+			return err
+		}
+
+		body.closers = append(body.closers, decoded)
+		r = decoded
+	}
+
+	body.Reader = r
+	res.Body = body
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+
+	return nil
+}
+
+// hasBody returns false, if the response has no body by definition or by its Content-Length.
+func hasBody(res *http.Response) bool {
+	if res.Request != nil && res.Request.Method == http.MethodHead {
+		return false
+	}
+
+	switch res.StatusCode {
+	case http.StatusNoContent, http.StatusNotModified:
+		return false
+	default:
+		return res.ContentLength != 0
+	}
+}
+
+// lookupDecoders returns the decoders of all codings or nil, if any of them is unknown.
+func lookupDecoders(codings []string) []ContentDecoder {
+	contentDecoders.mutex.Lock()
+	defer contentDecoders.mutex.Unlock()
+
+	decoders := make([]ContentDecoder, len(codings))
+
+	for i, coding := range codings {
+		decoders[i] = contentDecoders.byKey[coding]
+		if decoders[i] == nil {
+			return nil
+		}
+	}
+
+	return decoders
+}
+
+// decodedBody reads the decoded body and closes all decoders and the original body.
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes the decoders and the original body.
+func (b *decodedBody) Close() error {
+	var err error
+
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if closeErr := b.closers[i].Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
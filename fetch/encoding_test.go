@@ -0,0 +1,96 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestWithAcceptEncoding(t *testing.T) {
+	var compressed bytes.Buffer
+
+	w := gzip.NewWriter(&compressed)
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		encoding string
+		body     []byte
+		want     string
+	}{
+		{name: "gzip", method: http.MethodGet, status: http.StatusOK, encoding: "gzip", body: compressed.Bytes(),
+			want: "hello"},
+		{name: "unknown coding", method: http.MethodGet, status: http.StatusOK, encoding: "br", body: []byte("raw"),
+			want: "raw"},
+		{name: "identity", method: http.MethodGet, status: http.StatusOK, encoding: "identity", body: []byte("raw"),
+			want: "raw"},
+		{name: "head", method: http.MethodHead, status: http.StatusOK, encoding: "gzip"},
+		{name: "no content", method: http.MethodGet, status: http.StatusNoContent, encoding: "gzip"},
+		{name: "not modified", method: http.MethodGet, status: http.StatusNotModified, encoding: "gzip"},
+		{name: "empty", method: http.MethodGet, status: http.StatusOK, encoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+				res := MockResponse(tt.status, string(tt.body))
+				res.Header.Set("Content-Encoding", tt.encoding)
+
+				return res, nil
+			})
+
+			req, err := NewRequest("http://localhost/", WithMethod(tt.method), WithAcceptEncoding("gzip"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				got    []byte
+				gotErr error
+			)
+
+			done := make(chan struct{})
+
+			Request(client, req, func(res *http.Response, err error) {
+				defer close(done)
+
+				if gotErr = err; err == nil {
+					got, gotErr = io.ReadAll(res.Body)
+				}
+			})
+			<-done
+
+			if gotErr != nil {
+				t.Fatalf("expected no error, got %v", gotErr)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("expected '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
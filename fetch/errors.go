@@ -0,0 +1,196 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime/debug"
+)
+
+// ErrInvalidCredentials is returned by authorization options, if the given credentials cannot be encoded into
+// a well-formed Authorization header.
+var ErrInvalidCredentials = errors.New("fetch: invalid credentials")
+
+// ErrInvalidOption is returned by an Option or passed to the callback of a function, which has been configured with
+// an invalid value.
+var ErrInvalidOption = errors.New("fetch: invalid option")
+
+// ErrInvalidRange is returned for a malformed byte range or Content-Range header.
+var ErrInvalidRange = errors.New("fetch: invalid range")
+
+// ErrOffline is wrapped by a *NetworkError, if FailFastOffline is enabled and the browser reports to be offline.
+var ErrOffline = errors.New("fetch: offline")
+
+// ErrEventSource is wrapped by the errors of an EventSource, if the connection has been interrupted or closed.
+var ErrEventSource = errors.New("fetch: event source error")
+
+// ErrAborted is passed to a request callback, if the request has been aborted by an AbortSignal.
+var ErrAborted = errors.New("fetch: aborted")
+
+// ErrBodyTooLarge is returned while reading a body which exceeds the limit of LimitBody or if the body of a request
+// exceeds the limit of WithKeepalive.
+var ErrBodyTooLarge = errors.New("fetch: body too large")
+
+// ErrCircuitOpen is wrapped by a *NetworkError, if a client of BreakerClient fails fast because of an open circuit.
+var ErrCircuitOpen = errors.New("fetch: circuit open")
+
+// ErrInvalidImage is wrapped by the error of AsImage, if the browser cannot decode the image.
+var ErrInvalidImage = errors.New("fetch: invalid image")
+
+// ErrTooManyPages is returned by GetAllPages, if the pagination exceeds MaxPages.
+var ErrTooManyPages = errors.New("fetch: too many pages")
+
+// ErrTimeout is matched by a *TimeoutError, see errors.Is.
+var ErrTimeout = errors.New("fetch: timeout")
+
+// ErrGRPCWeb is wrapped by the errors of UnaryGRPC, if the response is not a valid gRPC-Web response.
+var ErrGRPCWeb = errors.New("fetch: invalid grpc-web response")
+
+// ErrTooManyRedirects is wrapped by a *NetworkError, if a request exceeds the limit of WithMaxRedirects.
+var ErrTooManyRedirects = errors.New("fetch: too many redirects")
+
+// ErrInvalidCurl is wrapped by the errors of FromCurl, if the command line cannot be parsed.
+var ErrInvalidCurl = errors.New("fetch: invalid curl command")
+
+// ErrNotJSONArray is returned by AsJSONArray, if the body does not contain a JSON array.
+var ErrNotJSONArray = errors.New("fetch: not a json array")
+
+// ErrBudgetExceeded is returned for requests, which have not been sent, because the budget of SetByteBudget has been
+// reached.
+var ErrBudgetExceeded = errors.New("fetch: byte budget exceeded")
+
+// PanicError is passed to a request callback, if the request has panicked before the callback could be invoked.
+type PanicError struct {
+	// Value is the recovered value.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+// newPanicError captures the stack of the current (recovering) goroutine.
+func newPanicError(r interface{}) *PanicError {
+	return &PanicError{Value: r, Stack: debug.Stack()}
+}
+
+// Error returns the recovered value and the stack trace.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("fetch: recovered panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the recovered value, if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// HTTPError describes a response, whose status code has not been accepted, e.g. by EnsureStatus.
+type HTTPError struct {
+	// StatusCode is the http status code of the response, e.g. 404.
+	StatusCode int
+	// Status is the http status line of the response, e.g. "404 Not Found".
+	Status string
+	// Body contains the beginning of the response body, at most maxErrorBodySize bytes, which is useful
+	// for debugging.
+	Body []byte
+}
+
+// Error returns the status and the body snippet.
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("fetch: unexpected http status: %s", e.Status)
+	}
+
+	return fmt.Sprintf("fetch: unexpected http status: %s: %s", e.Status, e.Body)
+}
+
+// NetworkError is passed to a request callback, if the request could not be completed at all, e.g. because the
+// browser is offline, the host is not resolvable or the request has been blocked by CORS. Use errors.As to
+// distinguish it from a *HTTPError. The message contains the method and url of the failed request.
+type NetworkError struct {
+	// Method of the failed request.
+	Method string
+	// URL of the failed request.
+	URL string
+	// Err is the actual error returned by the http client, usually a *url.Error.
+	Err error
+}
+
+// Error returns the wrapped error message, prefixed by the method and url of the request. The redundant method and
+// url of a wrapped *url.Error are omitted.
+func (e *NetworkError) Error() string {
+	if e.Method == "" && e.URL == "" {
+		return fmt.Sprintf("fetch: network error: %v", e.Err)
+	}
+
+	cause := e.Err
+	if urlErr, ok := cause.(*url.Error); ok { //nolint:errorlint
+		cause = urlErr.Err
+	}
+
+	return fmt.Sprintf("fetch: network error: %s %s: %v", e.Method, e.URL, cause)
+}
+
+// Unwrap returns the wrapped error.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError is passed to a request callback, if the request has not been completed in time, either because the
+// deadline of its context has been exceeded or because the http client timed out. It matches ErrTimeout and its
+// cause, e.g. context.DeadlineExceeded, using errors.Is. Aborted or cancelled requests are no timeouts.
+type TimeoutError struct {
+	// Err is the cause, e.g. context.DeadlineExceeded or a *NetworkError.
+	Err error
+}
+
+// Error returns the wrapped error message.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("fetch: timeout: %v", e.Err)
+}
+
+// Unwrap returns the wrapped error.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true for ErrTimeout.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// Timeout returns true, for compatibility with net.Error.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// asTimeout wraps the error into a *TimeoutError, if it has been caused by a timeout.
+func asTimeout(err error) error {
+	if err == nil || errors.Is(err, ErrTimeout) {
+		return err
+	}
+
+	var timeout interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &timeout) && timeout.Timeout()) {
+		return &TimeoutError{Err: err}
+	}
+
+	return err
+}
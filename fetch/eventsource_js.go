@@ -0,0 +1,90 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/golangee/wasm-net/internal/dispatch"
+)
+
+// eventSourceClosed is the readyState of a closed EventSource, which does not reconnect anymore.
+const eventSourceClosed = 2
+
+// EventSource connects to the given url using the browser EventSource for Server-Sent Events. Unnamed events are
+// delivered as "message" events and any further named events can be subscribed by their names. The events are
+// delivered in order and from a goroutine, like the request callbacks, so they do not race with the UI or DOM
+// Thread.
+//
+// The browser reconnects automatically after an interruption and sends the Last-Event-ID header with the id of the
+// last received event. Each interruption is reported to onError with an error wrapping ErrEventSource. Call the
+// returned function to close the connection permanently.
+func EventSource(url string, onEvent func(event, data, id string), onError func(err error),
+	events ...string) (close func()) {
+	queue := dispatch.New()
+	es := js.Global().Get("EventSource").New(url)
+
+	onMessage := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		evt := args[0]
+		event, data, id := evt.Get("type").String(), evt.Get("data").String(), evt.Get("lastEventId").String()
+
+		queue.Post(func() {
+			defer GlobalPanicHandler()
+
+			onEvent(event, data, id)
+		})
+
+		return nil
+	})
+
+	onFailure := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		err := fmt.Errorf("%w: reconnecting", ErrEventSource)
+		if es.Get("readyState").Int() == eventSourceClosed {
+			err = fmt.Errorf("%w: connection closed", ErrEventSource)
+		}
+
+		queue.Post(func() {
+			defer GlobalPanicHandler()
+
+			onError(err)
+		})
+
+		return nil
+	})
+
+	events = append([]string{"message"}, events...)
+	for _, event := range events {
+		es.Call("addEventListener", event, onMessage)
+	}
+
+	es.Call("addEventListener", "error", onFailure)
+
+	return func() {
+		es.Call("close")
+
+		for _, event := range events {
+			es.Call("removeEventListener", event, onMessage)
+		}
+
+		es.Call("removeEventListener", "error", onFailure)
+		onMessage.Release()
+		onFailure.Release()
+		queue.Close()
+	}
+}
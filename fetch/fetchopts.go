@@ -0,0 +1,155 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+)
+
+// The header keys, which are interpreted by the wasm transport of net/http as options for the fetch init object,
+// instead of being sent as actual headers. Other platforms do not send them at all, see stripFetchOptions.
+const (
+	jsFetchMode     = "js.fetch:mode"
+	jsFetchCreds    = "js.fetch:credentials"
+	jsFetchRedirect = "js.fetch:redirect"
+)
+
+// The header keys for the options of fetch, which are only understood by the FetchTransport.
+const (
+	jsFetchCache        = "js.fetch:cache"
+	jsFetchKeepalive    = "js.fetch:keepalive"
+	jsFetchResponseType = "js.fetch:responseType"
+)
+
+// maxKeepaliveBody is the limit of the browsers for the bodies of all pending keepalive requests.
+const maxKeepaliveBody = 64 * 1024
+
+// WithCredentials sets the credentials option of fetch, which is one of omit, same-origin (default) or include.
+// Use include to send cookies with cross-origin requests.
+func WithCredentials(credentials string) Option {
+	return withFetchOption(jsFetchCreds, credentials, "omit", "same-origin", "include")
+}
+
+// WithMode sets the mode option of fetch, which is one of cors, no-cors, same-origin or navigate.
+func WithMode(mode string) Option {
+	return withFetchOption(jsFetchMode, mode, "cors", "no-cors", "same-origin", "navigate")
+}
+
+// WithRedirect sets the redirect option of fetch, which is one of follow (default), error or manual.
+func WithRedirect(redirect string) Option {
+	return withFetchOption(jsFetchRedirect, redirect, "follow", "error", "manual")
+}
+
+// WithCache sets the cache option of fetch, which is one of default, no-store, reload, no-cache, force-cache or
+// only-if-cached. In contrast to the other fetch options, it requires a client using the FetchTransport, which may
+// have been wrapped by the functions of this package, like SingleFlightClient. Otherwise, it is ignored.
+func WithCache(cache string) Option {
+	return withFetchOption(jsFetchCache, cache, "default", "no-store", "reload", "no-cache", "force-cache",
+		"only-if-cached")
+}
+
+// WithKeepalive sets the keepalive option of fetch, so that the request is completed by the browser, even if
+// the page is unloaded in the meantime, e.g. for a final log message. The browsers limit the bodies of all pending
+// keepalive requests to 64KiB, so a larger body is rejected with ErrBodyTooLarge, without sending it. Like WithCache,
+// it requires a client using the FetchTransport and is ignored otherwise. See also SendBeacon.
+func WithKeepalive(keepalive bool) Option {
+	return func(b *builder) error {
+		b.header.Set(jsFetchKeepalive, strconv.FormatBool(keepalive))
+
+		return nil
+	}
+}
+
+// WithResponseType selects the method of the fetch response, which reads the body: arrayBuffer (default), text or
+// blob. The body is still provided as bytes, but AsBlob hands out a blob response without copying it into the Go
+// heap. Like WithCache, it requires a client using the FetchTransport and is ignored otherwise.
+func WithResponseType(responseType string) Option {
+	return withFetchOption(jsFetchResponseType, responseType, "arrayBuffer", "text", "blob")
+}
+
+// WithRedirectPolicy replaces the CheckRedirect function of the client for this request, e.g. to inspect each hop.
+// Returning http.ErrUseLastResponse passes the redirect response itself to the callback, so that its Location header
+// can be read. Note, that the fetch API of the browser follows redirects internally, so the policy is never
+// consulted by the wasm transports, which only report the final url by res.Request.URL. To handle redirects within
+// the browser, use WithRedirect("error") or WithRedirect("manual"), whose opaque response hides the Location.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) Option {
+	return func(b *builder) error {
+		b.checkRedirect = policy
+
+		return nil
+	}
+}
+
+// WithMaxRedirects limits the amount of redirects, which are followed, before the request fails with
+// ErrTooManyRedirects, see also WithRedirectPolicy. A limit of 0 additionally sets WithRedirect("error"), which is
+// the only limit the browser fetch API can enforce.
+func WithMaxRedirects(n int) Option {
+	return func(b *builder) error {
+		if n < 0 {
+			return fmt.Errorf("invalid value '%d' for max redirects: %w", n, ErrInvalidOption)
+		}
+
+		if n == 0 {
+			b.header.Set(jsFetchRedirect, "error")
+		}
+
+		b.checkRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) > n {
+				return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, n)
+			}
+
+			return nil
+		}
+
+		return nil
+	}
+}
+
+// withFetchOption sets the header key for the transport, if the value is one of the valid values.
+func withFetchOption(key, value string, valid ...string) Option {
+	return func(b *builder) error {
+		for _, v := range valid {
+			if v == value {
+				b.header.Set(key, value)
+
+				return nil
+			}
+		}
+
+		return fmt.Errorf("invalid value '%s' for %s: %w", value, key, ErrInvalidOption)
+	}
+}
+
+// stripFetchOptions removes the pseudo headers of the fetch options, which are not understood by the transport of the
+// client. Otherwise, the transport of net/http rejects them as invalid header names or, within the browser, passes
+// them to the headers of fetch, which throws.
+func stripFetchOptions(client *http.Client, req *http.Request) {
+	if understandsFetchOptions(client.Transport) {
+		return
+	}
+
+	keys := []string{jsFetchCache, jsFetchKeepalive, jsFetchResponseType}
+	if runtime.GOOS != "js" {
+		// only the wasm transport of net/http understands these options
+		keys = append(keys, jsFetchMode, jsFetchCreds, jsFetchRedirect)
+	}
+
+	for _, key := range keys {
+		req.Header.Del(key)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fetchOptionsRoundTripFunc is a transport for tests, which understands the fetch options like the FetchTransport.
+type fetchOptionsRoundTripFunc RoundTripFunc
+
+func (f fetchOptionsRoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (f fetchOptionsRoundTripFunc) supportsFetchOptions() bool {
+	return true
+}
+
+func TestFetchOptionsWithDefaultClient(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		key  string
+	}{
+		{name: "cache", opt: WithCache("no-store"), key: jsFetchCache},
+		{name: "mode", opt: WithMode("cors"), key: jsFetchMode},
+		{name: "credentials", opt: WithCredentials("include"), key: jsFetchCreds},
+		{name: "redirect", opt: WithRedirect("follow"), key: jsFetchRedirect},
+		{name: "keepalive", opt: WithKeepalive(true), key: jsFetchKeepalive},
+		{name: "response type", opt: WithResponseType("blob"), key: jsFetchResponseType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+			}))
+			defer server.Close()
+
+			req, err := NewRequest(server.URL, tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := <-RequestChan(http.DefaultClient, req)
+			if res.Err != nil {
+				t.Fatalf("expected the option to be ignored, got %v", res.Err)
+			}
+
+			if res.Res.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", res.Res.StatusCode)
+			}
+
+			if _, ok := header[tt.key]; ok {
+				t.Fatalf("expected the fetch option %s not to be sent", tt.key)
+			}
+		})
+	}
+}
+
+func TestFetchOptionsWithFetchTransport(t *testing.T) {
+	var header http.Header
+
+	transport := fetchOptionsRoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		header = req.Header.Clone()
+
+		return MockResponse(http.StatusOK, ""), nil
+	})
+
+	// wrapping keeps the options, if the wrapped transport understands them
+	client := SingleFlightClient(&http.Client{Transport: transport})
+
+	req, err := NewRequest("http://localhost/", WithCache("reload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := <-RequestChan(client, req); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+
+	if got := header.Get(jsFetchCache); got != "reload" {
+		t.Fatalf("expected the cache option to be passed, got '%s'", got)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PostForm performs a http POST with the url encoded values as body and the Content-Type
+// application/x-www-form-urlencoded.
+func PostForm(url string, values url.Values, f func(res *http.Response, err error)) {
+	Post(url, "application/x-www-form-urlencoded", strings.NewReader(values.Encode()), f)
+}
+
+// AsForm is a middleware for an async http response, which parses an application/x-www-form-urlencoded body.
+func AsForm(f func(values url.Values, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		values, err := url.ParseQuery(string(buf))
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		f(values, nil)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"bytes"
+	"net/http"
+	"syscall/js"
+)
+
+// PostFormElement posts the fields of the given HTML form element, exactly as the browser would submit it. The
+// fields, including the files of any file input, are collected by a FormData and serialized by the browser into a
+// multipart/form-data body. The serialization is awaited within a new goroutine, so the callback is always invoked
+// asynchronously. If the value is not a form element, the js.Error is passed to the callback.
+func PostFormElement(url string, form js.Value, f func(res *http.Response, err error)) {
+	go func() {
+		defer GlobalPanicHandler()
+
+		body, contentType, err := serializeForm(form)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		Post(url, contentType, bytes.NewReader(body), f)
+	}()
+}
+
+// serializeForm encodes the form element as multipart body by using a Response of its FormData, which provides the
+// body and the Content-Type including the boundary.
+func serializeForm(form js.Value) (body []byte, contentType string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			jsErr, ok := r.(js.Error)
+			if !ok {
+				panic(r)
+			}
+
+			body, contentType, err = nil, "", jsErr
+		}
+	}()
+
+	response := js.Global().Get("Response").New(js.Global().Get("FormData").New(form))
+	contentType = response.Get("headers").Call("get", "Content-Type").String()
+
+	value, err := await(response.Call("arrayBuffer"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return arrayBufferBytes(value), contentType, nil
+}
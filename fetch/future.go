@@ -0,0 +1,132 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Future is the promise-like result of a request created by RequestFuture. It is resolved exactly once and all
+// handlers are invoked one after another in the order of their registration, also if registered after the
+// resolution. A panicking handler is passed to the GlobalPanicHandler and does not affect the other handlers.
+// Handlers never race with the UI or DOM Thread.
+type Future struct {
+	mutex    sync.Mutex
+	resolved bool
+	draining bool
+	result   Result
+	handlers []func(result Result)
+}
+
+// RequestFuture performs the request like Request and returns a Future. Like for RequestChan, the body of the
+// response has already been read into memory, when it is passed to the handlers. Note, that all handlers share the
+// same response and thus the same body.
+func RequestFuture(client *http.Client, req *http.Request) *Future {
+	future := &Future{}
+
+	Request(client, req, func(res *http.Response, err error) {
+		if err == nil {
+			err = bufferBody(res)
+		}
+
+		if err != nil {
+			res = nil
+		}
+
+		future.resolve(Result{Res: res, Err: err})
+	})
+
+	return future
+}
+
+// Then registers a handler, which is invoked if the request succeeded.
+func (f *Future) Then(handler func(res *http.Response)) *Future {
+	return f.register(func(result Result) {
+		if result.Err == nil {
+			handler(result.Res)
+		}
+	})
+}
+
+// Catch registers a handler, which is invoked if the request failed.
+func (f *Future) Catch(handler func(err error)) *Future {
+	return f.register(func(result Result) {
+		if result.Err != nil {
+			handler(result.Err)
+		}
+	})
+}
+
+// Finally registers a handler, which is always invoked.
+func (f *Future) Finally(handler func()) *Future {
+	return f.register(func(Result) {
+		handler()
+	})
+}
+
+// register queues the handler. If already resolved, the queue is drained from a new goroutine, unless it is already
+// being drained.
+func (f *Future) register(handler func(result Result)) *Future {
+	f.mutex.Lock()
+	f.handlers = append(f.handlers, handler)
+	start := f.resolved && !f.draining
+	f.draining = f.draining || start
+	f.mutex.Unlock()
+
+	if start {
+		go f.drain()
+	}
+
+	return f
+}
+
+// resolve stores the result and invokes the queued handlers.
+func (f *Future) resolve(result Result) {
+	f.mutex.Lock()
+	f.resolved = true
+	f.result = result
+	f.draining = true
+	f.mutex.Unlock()
+
+	f.drain()
+}
+
+// drain invokes the queued handlers one after another, until the queue is empty.
+func (f *Future) drain() {
+	for {
+		f.mutex.Lock()
+		if len(f.handlers) == 0 {
+			f.draining = false
+			f.mutex.Unlock()
+
+			return
+		}
+
+		handler := f.handlers[0]
+		f.handlers = f.handlers[1:]
+		result := f.result
+		f.mutex.Unlock()
+
+		invokeHandler(handler, result)
+	}
+}
+
+// invokeHandler invokes the handler and recovers from its panic.
+func invokeHandler(handler func(result Result), result Result) {
+	defer GlobalPanicHandler()
+
+	handler(result)
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFutureHandlerOrder(t *testing.T) {
+	logger := Logger
+	Logger = func(...interface{}) {} // silence the expected panic
+	t.Cleanup(func() { Logger = logger })
+
+	tests := []struct {
+		name  string
+		after bool
+	}{
+		{name: "registered before resolution", after: false},
+		{name: "registered after resolution", after: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				testFutureHandlerOrder(t, tt.after)
+			}
+		})
+	}
+}
+
+func testFutureHandlerOrder(t *testing.T, after bool) {
+	t.Helper()
+
+	release := make(chan struct{})
+	resolved := make(chan struct{})
+
+	client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+		<-release
+
+		return MockResponse(http.StatusOK, "hello"), nil
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future := RequestFuture(client, req)
+
+	if after {
+		future.Finally(func() { close(resolved) })
+		close(release)
+		<-resolved
+	}
+
+	var (
+		mutex sync.Mutex
+		calls []string
+	)
+
+	record := func(call string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		calls = append(calls, call)
+	}
+
+	done := make(chan struct{})
+
+	future.
+		Then(func(res *http.Response) { record("then") }).
+		Then(func(res *http.Response) { panic("expected") }).
+		Catch(func(err error) { record("catch") }).
+		Finally(func() { record("finally") }).
+		Finally(func() { close(done) })
+
+	if !after {
+		close(release)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected all handlers to be invoked")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if want := []string{"then", "finally"}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("expected %v, got %v", want, calls)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	grpcFrameHeaderSize = 5
+	grpcFlagCompressed  = 0x01
+	grpcFlagTrailer     = 0x80
+)
+
+// UnaryGRPC performs a unary gRPC-Web call by posting the already marshalled message to the url, which is the
+// full method path, e.g. https://example.com/my.pkg.Service/Method. The callback receives the marshalled response
+// message, the grpc-status and all trailers with lowercase keys, e.g. grpc-message. A status other than 0 (OK) is
+// not an error, because the trailers describe it. The error is reserved for failures of the request, statuses
+// other than 2xx (as *HTTPError) and malformed responses, which wrap ErrGRPCWeb. Compressed messages are not
+// supported.
+func UnaryGRPC(url string, msg []byte, f func(resp []byte, status int, trailers map[string]string, err error)) {
+	frame := make([]byte, grpcFrameHeaderSize+len(msg))
+	binary.BigEndian.PutUint32(frame[1:grpcFrameHeaderSize], uint32(len(msg)))
+	copy(frame[grpcFrameHeaderSize:], msg)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(frame))
+	if err != nil {
+		f(nil, 0, nil, err)
+
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("Accept", "application/grpc-web+proto")
+	req.Header.Set("X-Grpc-Web", "1")
+
+	Request(DefaultClient(), req, func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, 0, nil, err)
+
+			return
+		}
+
+		if !isSuccess(res.StatusCode) {
+			f(nil, 0, nil, newHTTPError(res))
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(nil, 0, nil, err)
+
+			return
+		}
+
+		f(parseGRPCWeb(res.Header, buf))
+	})
+}
+
+// parseGRPCWeb parses the message and trailer frames of the body. A trailers-only response carries the status
+// within its headers.
+func parseGRPCWeb(header http.Header, body []byte) (resp []byte, status int, trailers map[string]string, err error) {
+	trailers = map[string]string{}
+
+	for _, key := range []string{"grpc-status", "grpc-message"} {
+		if value := header.Get(key); value != "" {
+			trailers[key] = value
+		}
+	}
+
+	for len(body) > 0 {
+		if len(body) < grpcFrameHeaderSize {
+			return nil, 0, nil, fmt.Errorf("%w: truncated frame header", ErrGRPCWeb)
+		}
+
+		flags := body[0]
+		size := binary.BigEndian.Uint32(body[1:grpcFrameHeaderSize])
+		body = body[grpcFrameHeaderSize:]
+
+		if uint64(size) > uint64(len(body)) {
+			return nil, 0, nil, fmt.Errorf("%w: truncated frame", ErrGRPCWeb)
+		}
+
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case flags&grpcFlagCompressed != 0:
+			return nil, 0, nil, fmt.Errorf("%w: compressed frames are not supported", ErrGRPCWeb)
+		case flags&grpcFlagTrailer != 0:
+			for _, line := range strings.Split(string(data), "\r\n") {
+				if colon := strings.Index(line, ":"); colon > 0 {
+					trailers[strings.ToLower(strings.TrimSpace(line[:colon]))] = strings.TrimSpace(line[colon+1:])
+				}
+			}
+		case resp == nil:
+			resp = data
+		default:
+			return nil, 0, nil, fmt.Errorf("%w: more than one message in a unary response", ErrGRPCWeb)
+		}
+	}
+
+	value, ok := trailers["grpc-status"]
+	if !ok {
+		return nil, 0, nil, fmt.Errorf("%w: missing grpc-status", ErrGRPCWeb)
+	}
+
+	status, err = strconv.Atoi(value)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: invalid grpc-status '%s'", ErrGRPCWeb, value)
+	}
+
+	if resp == nil && status == 0 {
+		resp = []byte{}
+	}
+
+	return resp, status, trailers, nil
+}
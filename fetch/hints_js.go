@@ -0,0 +1,70 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"sync"
+	"syscall/js"
+)
+
+// hints contains the relations and urls of all added link tags, see addHint.
+var hints struct { //nolint:gochecknoglobals
+	mutex sync.Mutex
+	added map[string]bool
+}
+
+// Preconnect adds a <link rel="preconnect"> for the url to the head of the document, so that the browser can
+// resolve and connect to the origin before the first request is made. Repeated calls for the same url are ignored.
+// Outside of a document, e.g. within a web worker, this is a no-op.
+func Preconnect(url string) {
+	addHint("preconnect", url)
+}
+
+// Prefetch adds a <link rel="prefetch"> for the url to the head of the document, so that the browser may load the
+// resource with a low priority into its cache. Repeated calls for the same url are ignored. Outside of a document,
+// e.g. within a web worker, this is a no-op.
+func Prefetch(url string) {
+	addHint("prefetch", url)
+}
+
+// addHint appends a link tag with the given relation, if not already added.
+func addHint(rel, url string) {
+	doc := js.Global().Get("document")
+	if doc.IsUndefined() || doc.Get("head").IsNull() {
+		return
+	}
+
+	hints.mutex.Lock()
+	defer hints.mutex.Unlock()
+
+	key := rel + " " + url
+	if hints.added[key] {
+		return
+	}
+
+	if hints.added == nil {
+		hints.added = map[string]bool{}
+	}
+
+	hints.added[key] = true
+
+	link := doc.Call("createElement", "link")
+	link.Set("rel", rel)
+	link.Set("href", url)
+	doc.Get("head").Call("appendChild", link)
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks observe requests, e.g. to export latency metrics. Each hook is optional and is invoked from the goroutine of
+// the request, before the callback, so it does not race with it. The duration is measured from sending the request
+// until the response headers have been received.
+type Hooks struct {
+	// OnStart is invoked, before the request is sent.
+	OnStart func(req *http.Request)
+	// OnResponse is invoked, when a response has been received, regardless of its status code.
+	OnResponse func(res *http.Response, d time.Duration)
+	// OnError is invoked, when the request failed.
+	OnError func(err error, d time.Duration)
+}
+
+// GlobalHooks are applied to all requests performed by Request, including each attempt of a Retry. A request is
+// observed as a whole, including its redirects. They should only be set once at startup.
+var GlobalHooks Hooks //nolint:gochecknoglobals
+
+// HookedClient returns a copy of the given client, which applies the hooks to each round trip of its transport. Like
+// GlobalHooks, each attempt of a Retry is observed individually, but in contrast to them, each hop of a redirect is
+// observed as well, instead of the request as a whole. A nil client is treated as the DefaultClient.
+func HookedClient(client *http.Client, hooks Hooks) *http.Client {
+	return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return hooks.observe(req, func() (*http.Response, error) {
+				return transport.RoundTrip(req)
+			})
+		})
+	})
+}
+
+// observe invokes the hooks around the given round trip.
+func (h Hooks) observe(req *http.Request, roundTrip func() (*http.Response, error)) (*http.Response, error) {
+	if h.OnStart != nil {
+		h.OnStart(req)
+	}
+
+	start := time.Now()
+	res, err := roundTrip()
+	d := time.Since(start)
+
+	if err != nil {
+		if h.OnError != nil {
+			h.OnError(err, d)
+		}
+
+		return nil, err
+	}
+
+	if h.OnResponse != nil {
+		h.OnResponse(res, d)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHooksRedirect(t *testing.T) {
+	tests := []struct {
+		name   string
+		global bool
+		want   int
+	}{
+		{name: "global hooks observe the request as a whole", global: true, want: 1},
+		{name: "hooked client observes each hop", global: false, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var started int
+
+			hooks := Hooks{OnStart: func(req *http.Request) { started++ }}
+
+			client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path == "/old" {
+					res := MockResponse(http.StatusFound, "")
+					res.Header.Set("Location", "/new")
+
+					return res, nil
+				}
+
+				return MockResponse(http.StatusOK, "hello"), nil
+			})
+
+			if tt.global {
+				global := GlobalHooks
+				GlobalHooks = hooks
+				t.Cleanup(func() { GlobalHooks = global })
+			} else {
+				client = HookedClient(client, hooks)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/old", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := <-RequestChan(client, req)
+			if res.Err != nil {
+				t.Fatal(res.Err)
+			}
+
+			if res.Res.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", res.Res.StatusCode)
+			}
+
+			if started != tt.want {
+				t.Fatalf("expected %d observed requests, got %d", tt.want, started)
+			}
+		})
+	}
+}
@@ -15,14 +15,28 @@
 package fetch
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"reflect"
 	"runtime/debug"
+	"time"
 )
 
+// Logger is used by the panic handler and any other internal logging. It defaults to log.Println and can be replaced
+// to forward the messages into a custom sink. Setting it to nil silences all logging.
+var Logger = log.Println //nolint:gochecknoglobals
+
+// FailFastOffline determines, if Request shall not issue any request, while IsOnline returns false. Instead, the
+// callback immediately receives a *NetworkError wrapping ErrOffline.
+var FailFastOffline = false //nolint:gochecknoglobals
+
 // GlobalPanicHandler is introduced to remove a dependency to the dom package and avoids halting
 // the entire wasm process if an internally spawned goroutine panics.
 var GlobalPanicHandler = func() { //nolint:gochecknoglobals
@@ -31,41 +45,323 @@ var GlobalPanicHandler = func() { //nolint:gochecknoglobals
 		return
 	}
 
-	log.Println(r, string(debug.Stack()))
+	logPrint(r, string(debug.Stack()))
+}
+
+// logPrint delegates to the current Logger, if any.
+func logPrint(args ...interface{}) {
+	if Logger != nil {
+		Logger(args...)
+	}
 }
 
 // Get performs a simple http.Get (Fetch) and returns the response.
 func Get(url string, f func(res *http.Response, err error)) {
-	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	GetContext(context.Background(), url, f)
+}
+
+// GetContext is like Get but uses the given context for the request, which is available to any middleware using
+// res.Request.Context(). Cancelling it aborts the request, see also Request.
+func GetContext(ctx context.Context, url string, f func(res *http.Response, err error)) {
+	send(ctx, http.MethodGet, url, "", nil, f)
+}
+
+// GetTimeout is like Get but aborts the request, if it is not completed within the given timeout. In that case
+// the callback receives a *TimeoutError, which matches ErrTimeout and context.DeadlineExceeded. Note, that the
+// timeout also applies while reading the body within the callback.
+func GetTimeout(url string, timeout time.Duration, f func(res *http.Response, err error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		cancel()
+		f(nil, err)
+
+		return
+	}
+
+	Request(DefaultClient(), req, func(res *http.Response, err error) {
+		defer cancel()
+
+		f(res, err)
+	})
+}
+
+// Post performs a http POST (Fetch) with the given body and returns the response. The Content-Type header is only
+// set, if contentType is not empty.
+func Post(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	PostContext(context.Background(), url, contentType, body, f)
+}
+
+// PostContext is like Post but uses the given context for the request, see also GetContext.
+func PostContext(ctx context.Context, url string, contentType string, body io.Reader,
+	f func(res *http.Response, err error)) {
+	send(ctx, http.MethodPost, url, contentType, body, f)
+}
+
+// Put performs a http PUT (Fetch) with the given body and returns the response. See also Post.
+func Put(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	PutContext(context.Background(), url, contentType, body, f)
+}
+
+// PutContext is like Put but uses the given context for the request, see also GetContext.
+func PutContext(ctx context.Context, url string, contentType string, body io.Reader,
+	f func(res *http.Response, err error)) {
+	send(ctx, http.MethodPut, url, contentType, body, f)
+}
+
+// Patch performs a http PATCH (Fetch) with the given body and returns the response. See also Post.
+func Patch(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	PatchContext(context.Background(), url, contentType, body, f)
+}
+
+// PatchContext is like Patch but uses the given context for the request, see also GetContext.
+func PatchContext(ctx context.Context, url string, contentType string, body io.Reader,
+	f func(res *http.Response, err error)) {
+	send(ctx, http.MethodPatch, url, contentType, body, f)
+}
+
+// Delete performs a http DELETE (Fetch) and returns the response. Usually a delete has no body, so body may be nil.
+// See also Post.
+func Delete(url string, contentType string, body io.Reader, f func(res *http.Response, err error)) {
+	DeleteContext(context.Background(), url, contentType, body, f)
+}
+
+// DeleteContext is like Delete but uses the given context for the request, see also GetContext.
+func DeleteContext(ctx context.Context, url string, contentType string, body io.Reader,
+	f func(res *http.Response, err error)) {
+	send(ctx, http.MethodDelete, url, contentType, body, f)
+}
+
+// PostJSON marshals the given body using json.Marshal and performs a http POST with the Content-Type
+// application/json. A nil body, including a typed nil like a nil pointer, map or slice, is sent as an empty body and
+// not as the literal null. A marshalling error is passed to the callback, without issuing any request.
+func PostJSON(url string, body interface{}, f func(res *http.Response, err error)) {
+	var buf []byte
+
+	if !isNil(body) {
+		b, err := json.Marshal(body)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		buf = b
+	}
+
+	Post(url, "application/json", bytes.NewReader(buf), f)
+}
+
+// PostJSONFor performs a http POST of the body like PostJSON and decodes the JSON response into a new value of type
+// Resp like JSON. A status code other than 2xx is passed as *HTTPError, see EnsureStatus. Example:
+//
+//	fetch.PostJSONFor("https://my.domain/users", NewUser{Name: "Alice"}, func(user User, err error) {
+//		// user is only valid, if err is nil
+//	})
+func PostJSONFor[Req, Resp any](url string, body Req, f func(resp Resp, err error)) {
+	PostJSON(url, body, EnsureStatus(JSON(f)))
+}
+
+// isNil returns true, if v is nil or a nil pointer, map, slice or interface.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// send creates a request for the given method and delegates to Request using the DefaultClient.
+func send(ctx context.Context, method, url string, contentType string, body io.Reader,
+	f func(res *http.Response, err error)) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		f(nil, err)
 
 		return
 	}
 
-	Request(http.DefaultClient, req, f)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	Request(DefaultClient(), req, f)
+}
+
+// RequestContext is like Request but replaces the context of the request with the given one. See also Request. The
+// options of NewRequest, like WithTrace, are kept and their resources are released as usual. However, the
+// cancellation by WithSignal or SignalOnEvent only applies, if ctx has been derived from the context of the request.
+func RequestContext(ctx context.Context, client *http.Client, request *http.Request,
+	f func(res *http.Response, err error)) {
+	if cfg := configOf(request); cfg != nil {
+		ctx = context.WithValue(ctx, configKey{}, cfg)
+	}
+
+	Request(client, request.WithContext(ctx), f)
+}
+
+// RequestCancelable is like Request but returns a cancel function. Calling it before the callback has been invoked
+// aborts the fetch and the callback receives context.Canceled. Calling it afterwards has no effect.
+func RequestCancelable(client *http.Client, request *http.Request,
+	f func(res *http.Response, err error)) (cancel func()) {
+	ctx, cancel := context.WithCancel(request.Context())
+
+	RequestContext(ctx, client, request, func(res *http.Response, err error) {
+		defer cancel()
+
+		f(res, err)
+	})
+
+	return cancel
 }
 
 // Request is the generic http client implementation which allows custom requests. The current implementation spawns a
 // new goroutine for each request, but the callback is guaranteed not to race with the UI or DOM Thread. However,
 // the only guarantee is, that it does not deadlock.
+//
+// The context of the request is honored: cancelling it aborts the underlying fetch (the wasm transport of net/http
+// uses an AbortController) and the callback is invoked exactly once with the error of the context, e.g.
+// context.Canceled, even if a response has been received in the meantime. An exceeded deadline or a timeout of the
+// client is passed as *TimeoutError, which wraps the cause. If the request panics before the callback has been
+// invoked, the callback receives a *PanicError. Any other failure of the http client is passed as *NetworkError.
+// Note, that a response is not an error, regardless of its status code, see also EnsureStatus.
+//
+// The body of the response is closed, after the callback returns. So it must be consumed within the callback. An
+// unread remainder is not drained, which cancels the stream in the browser, but prevents the reuse of the connection
+// by other transports. Use Drain or WithAutoDrain, if this matters.
 func Request(client *http.Client, request *http.Request, f func(res *http.Response, err error)) {
+	do(client, request, f, nil)
+}
+
+// RequestWithRecover is like Request but invokes onPanic instead of the GlobalPanicHandler, if the callback panics.
+// This allows to correlate a panic with the request, e.g. for telemetry.
+func RequestWithRecover(client *http.Client, request *http.Request, f func(res *http.Response, err error),
+	onPanic func(recovered interface{})) {
+	do(client, request, f, onPanic)
+}
+
+// do implements Request using the given panic handler, which is the GlobalPanicHandler if nil.
+func do(client *http.Client, request *http.Request, f func(res *http.Response, err error),
+	onPanic func(recovered interface{})) {
+	f = configOf(request).decorate(client, request, f)
+
+	addInFlight(1)
+
 	go func() {
-		defer GlobalPanicHandler()
+		// deferred first, so that it runs last, even if the panic handler panics itself
+		defer addInFlight(-1)
 
-		res, err := client.Do(request)
+		if onPanic == nil {
+			defer GlobalPanicHandler()
+		} else {
+			defer func() {
+				if r := recover(); r != nil {
+					onPanic(r)
+				}
+			}()
+		}
+
+		invoked := false
+
+		// a panic before the callback has been invoked would otherwise leave the caller waiting forever. A panic
+		// within the callback is left to the panic handler.
+		defer func() {
+			if invoked {
+				return
+			}
+
+			if r := recover(); r != nil {
+				invoked = true
+				f(nil, newPanicError(r))
+			}
+		}()
+
+		res, err := roundTrip(client, request)
 		if err == nil {
 			defer res.Body.Close() //nolint:errcheck
 		}
 
+		if ctxErr := request.Context().Err(); ctxErr != nil {
+			res, err = nil, ctxErr
+		}
+
+		err = asTimeout(err)
+
 		// in a "normal" context, this would be a simple way to introduce data races, however the Go wasm
 		// implementation is currently only single threaded and even if that would not be the case
 		// in the future anymore, it is still unclear how we will evolve, perhaps directly using fetch
 		// instead of doing this kind of complex (and broken) roundtrip.
-		f(res, err)
+		invoked = true
+		schedule(f, res, err)
 	}()
 }
 
+// roundTrip applies the interceptors and performs the request. Any failure of the client is wrapped
+// as *NetworkError.
+func roundTrip(client *http.Client, request *http.Request) (*http.Response, error) {
+	if err := intercept(request); err != nil {
+		closeBody(request)
+
+		return nil, err
+	}
+
+	if FailFastOffline && !IsOnline() {
+		closeBody(request)
+
+		return nil, newNetworkError(request, ErrOffline)
+	}
+
+	if err := checkByteBudget(); err != nil {
+		closeBody(request)
+
+		return nil, err
+	}
+
+	applyUserAgent(request)
+	stripFetchOptions(client, request)
+
+	if cfg := configOf(request); cfg != nil && cfg.checkRedirect != nil {
+		cpy := *client
+		cpy.CheckRedirect = cfg.checkRedirect
+		client = &cpy
+	}
+
+	return GlobalHooks.observe(request, func() (*http.Response, error) {
+		res, err := client.Do(request)
+		if err != nil {
+			return nil, newNetworkError(request, err)
+		}
+
+		configOf(request).responded()
+		res.Body = &countingBody{ReadCloser: res.Body}
+
+		return res, nil
+	})
+}
+
+// newNetworkError wraps the error of the request into a *NetworkError.
+func newNetworkError(request *http.Request, err error) *NetworkError {
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return &NetworkError{Method: method, URL: request.URL.Redacted(), Err: err}
+}
+
+// closeBody closes the body of a request, which is not passed to the http.Client, which would otherwise close it.
+func closeBody(request *http.Request) {
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+}
+
 // AsText is a middleware for an async http response.
 func AsText(f func(res string, err error)) func(res *http.Response, err error) {
 	return func(res *http.Response, err error) {
@@ -86,6 +382,42 @@ func AsText(f func(res string, err error)) func(res *http.Response, err error) {
 	}
 }
 
+// AsBytes is a middleware for an async http response, which reads the entire body and returns it unmodified.
+func AsBytes(f func(data []byte, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		f(buf, nil)
+	}
+}
+
+// AsReader is a middleware for an async http response, which passes the body as a stream, instead of buffering it.
+// The body is owned by Request and is only valid until the callback returns, because Request closes it afterwards.
+// So the callback must consume the stream synchronously, which is fine, because it does not block the UI or DOM
+// Thread. Closing the reader early is allowed. If the body is required afterwards, use AsBytes instead.
+func AsReader(f func(r io.ReadCloser, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		f(res.Body, nil)
+	}
+}
+
 // AsJSON tries to unmarshal into given v and invokes the callback afterwards. The callback is always invoked
 // and if the err is nil, the given interface has been populated successfully. Example:
 //   type MyType struct{
@@ -124,3 +456,161 @@ func AsJSON(v interface{}, f func(err error)) func(res *http.Response, err error
 		f(nil) // success case
 	}
 }
+
+// JSON is a middleware for an async http response, which unmarshals the body into a new value of type T and passes
+// it to the callback. It works like AsJSON, but avoids declaring the target in advance:
+//
+//	fetch.Get("https://my.domain/user/1", fetch.JSON(func(user User, err error) {
+//		// user is only valid, if err is nil
+//	}))
+func JSON[T any](f func(v T, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		var v T
+
+		AsJSON(&v, func(err error) {
+			f(v, err)
+		})(res, err)
+	}
+}
+
+// Validate is a middleware like JSON, which additionally asserts the invariants of the decoded value with check,
+// e.g. the presence of required fields. The callback only succeeds, if the value has been decoded and check returned
+// nil. Otherwise, the decoding error or the unmodified error of check is passed along with the value:
+//
+//	fetch.Get("https://my.domain/user/1", fetch.Validate(func(user User) error {
+//		if user.ID == "" {
+//			return errors.New("missing id")
+//		}
+//
+//		return nil
+//	}, func(user User, err error) {
+//		// user is only valid, if err is nil
+//	}))
+func Validate[T any](check func(v T) error, f func(v T, err error)) func(res *http.Response, err error) {
+	return JSON(func(v T, err error) {
+		if err == nil {
+			err = check(v)
+		}
+
+		f(v, err)
+	})
+}
+
+// AsXML tries to unmarshal into given v and invokes the callback afterwards. It works exactly like AsJSON but
+// uses the encoding/xml package. An empty body is reported as *xml.SyntaxError and a nil v is rejected
+// by the xml package with an error instead of a panic.
+func AsXML(v interface{}, f func(err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		if err := xml.Unmarshal(buf, v); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = &xml.SyntaxError{Msg: "unexpected EOF", Line: 1}
+			}
+
+			f(err)
+
+			return
+		}
+
+		f(nil) // success case
+	}
+}
+
+// maxErrorBodySize is the limit of bytes from a body, which are kept in a HTTPError.
+const maxErrorBodySize = 4096
+
+// EnsureStatus is a middleware which only delegates successful responses with a 2xx status code to the next
+// handler. Otherwise, the body is drained and the next handler receives a *HTTPError.
+func EnsureStatus(next func(res *http.Response, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			next(nil, err)
+
+			return
+		}
+
+		if isSuccess(res.StatusCode) {
+			next(res, nil)
+
+			return
+		}
+
+		next(nil, newHTTPError(res))
+	}
+}
+
+// LimitBody is a middleware which limits the body to the given amount of bytes. Reading more than max bytes fails
+// with ErrBodyTooLarge instead of silently truncating the body, so it composes with AsText, AsJSON or AsBytes.
+// If the Content-Length already exceeds the limit, the next handler immediately receives ErrBodyTooLarge.
+func LimitBody(max int64, next func(res *http.Response, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			next(nil, err)
+
+			return
+		}
+
+		if res.ContentLength > max {
+			next(nil, ErrBodyTooLarge)
+
+			return
+		}
+
+		res.Body = &limitedBody{ReadCloser: res.Body, remaining: max}
+		next(res, nil)
+	}
+}
+
+// limitedBody fails with ErrBodyTooLarge, if more than remaining bytes are available.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read delegates to the body, until the limit has been reached. Afterwards it checks for the end of the body.
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		var probe [1]byte
+
+		n, err := l.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+
+		return 0, err //nolint:wrapcheck
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err //nolint:wrapcheck
+}
+
+// isSuccess returns true for any 2xx status code.
+func isSuccess(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+// newHTTPError reads a snippet of the response body and drains the rest of it.
+func newHTTPError(res *http.Response) *HTTPError {
+	snippet, _ := ioutil.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	_ = Drain(res)
+
+	return &HTTPError{StatusCode: res.StatusCode, Status: res.Status, Body: snippet}
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostJSONBody(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name string
+		body interface{}
+		want string
+	}{
+		{name: "nil", body: nil, want: ""},
+		{name: "nil pointer", body: (*payload)(nil), want: ""},
+		{name: "nil map", body: map[string]string(nil), want: ""},
+		{name: "nil slice", body: []string(nil), want: ""},
+		{name: "empty slice", body: []string{}, want: "[]"},
+		{name: "value", body: payload{Name: "Alice"}, want: `{"name":"Alice"}`},
+		{name: "pointer", body: &payload{Name: "Alice"}, want: `{"name":"Alice"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies := make(chan string, 1)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				buf, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Error(err)
+				}
+
+				bodies <- string(buf)
+			}))
+			defer server.Close()
+
+			done := make(chan error, 1)
+
+			PostJSON(server.URL, tt.body, func(res *http.Response, err error) {
+				done <- err
+			})
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("callback not invoked")
+			}
+
+			if got := <-bodies; got != tt.want {
+				t.Fatalf("expected body %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// withRelease is an option for tests, which invokes release, when the resources of the request are released.
+func withRelease(release func()) Option {
+	return func(b *builder) error {
+		b.scopes = append(b.scopes, func(ctx context.Context) (context.Context, func()) {
+			return ctx, release
+		})
+
+		return nil
+	}
+}
+
+func TestRequestContextKeepsConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  func(done func()) Option
+	}{
+		{name: "wrapper", opt: func(done func()) Option { return WithTrace(func(Trace) { done() }) }},
+		{name: "release", opt: withRelease},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+
+			req, err := NewRequest("http://localhost/", tt.opt(func() { close(done) }))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return MockResponse(http.StatusOK, "hello"), nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			RequestContext(ctx, client, req, func(res *http.Response, err error) {
+				if err != nil {
+					t.Error(err)
+				}
+			})
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("expected the option of the request to be applied")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// idempotencyKeyHeader is the header of WithIdempotencyKey.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sets the Idempotency-Key header, which allows the server to detect duplicates of the same
+// logical request. An empty key is replaced by a random UUID. Such a request is retried by Retry and
+// RetryWithPolicy regardless of its method, and all attempts send the same key.
+func WithIdempotencyKey(key string) Option {
+	return func(b *builder) error {
+		if key == "" {
+			uuid, err := newUUID()
+			if err != nil {
+				return err
+			}
+
+			key = uuid
+		}
+
+		b.header.Set(idempotencyKeyHeader, key)
+
+		return nil
+	}
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"syscall/js"
+)
+
+// AsImage is a middleware for an async http response, which decodes the body into a new HTMLImageElement. The
+// callback is invoked from a new goroutine, when the image has been loaded or could not be decoded. The object url
+// of the image is revoked at that time, so that the image element keeps its decoded data but its src must not be
+// reused.
+func AsImage(f func(img js.Value, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(js.Undefined(), err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(js.Undefined(), err)
+
+			return
+		}
+
+		url := createObjectURL(newBlob(buf, res.Header.Get("Content-Type")))
+		img := js.Global().Get("Image").New()
+
+		var onLoad, onError js.Func
+
+		done := func(err error) {
+			img.Set("onload", js.Null())
+			img.Set("onerror", js.Null())
+			onLoad.Release()
+			onError.Release()
+			revokeObjectURL(url)
+
+			go func() {
+				defer GlobalPanicHandler()
+
+				if err != nil {
+					f(js.Undefined(), err)
+
+					return
+				}
+
+				f(img, nil)
+			}()
+		}
+
+		onLoad = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			done(nil)
+
+			return nil
+		})
+
+		onError = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			done(fmt.Errorf("%w: content type '%s'", ErrInvalidImage, res.Header.Get("Content-Type")))
+
+			return nil
+		})
+
+		img.Set("onload", onLoad)
+		img.Set("onerror", onError)
+		img.Set("src", url)
+	}
+}
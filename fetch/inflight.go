@@ -0,0 +1,80 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"sync"
+
+	"github.com/golangee/wasm-net/internal/dispatch"
+)
+
+// inFlight counts the requests in flight, see InFlight.
+var inFlight struct { //nolint:gochecknoglobals
+	mutex       sync.Mutex
+	count       int
+	nextID      int
+	subscribers map[int]func(n int)
+	queue       *dispatch.Queue
+}
+
+// InFlight returns the number of requests performed by Request and the functions built upon it, whose callbacks
+// have not returned yet, e.g. to show a global loading indicator.
+func InFlight() int {
+	inFlight.mutex.Lock()
+	defer inFlight.mutex.Unlock()
+
+	return inFlight.count
+}
+
+// OnInFlightChange registers the given callback, which receives the new value of InFlight whenever it changes. The
+// callbacks are invoked in order from a separate goroutine, so they may perform requests themselves. Use the
+// returned function to remove the callback.
+func OnInFlightChange(f func(n int)) (unsubscribe func()) {
+	inFlight.mutex.Lock()
+	defer inFlight.mutex.Unlock()
+
+	if inFlight.subscribers == nil {
+		inFlight.subscribers = map[int]func(n int){}
+		inFlight.queue = dispatch.New()
+	}
+
+	id := inFlight.nextID
+	inFlight.nextID++
+	inFlight.subscribers[id] = f
+
+	return func() {
+		inFlight.mutex.Lock()
+		defer inFlight.mutex.Unlock()
+
+		delete(inFlight.subscribers, id)
+	}
+}
+
+// addInFlight changes the number of requests in flight by delta and notifies the subscribers.
+func addInFlight(delta int) {
+	inFlight.mutex.Lock()
+	defer inFlight.mutex.Unlock()
+
+	inFlight.count += delta
+	n := inFlight.count
+
+	for _, f := range inFlight.subscribers {
+		inFlight.queue.Post(func() {
+			defer GlobalPanicHandler()
+
+			f(n)
+		})
+	}
+}
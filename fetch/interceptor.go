@@ -0,0 +1,54 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"sync"
+)
+
+// A RequestInterceptor mutates an outgoing request, before it is sent, e.g. to inject a correlation id or an
+// authorization header. Returning an error cancels the request and the error is passed to the callback.
+type RequestInterceptor func(req *http.Request) error
+
+// interceptors contains all registered interceptors, see Use.
+var interceptors struct { //nolint:gochecknoglobals
+	mutex sync.Mutex
+	list  []RequestInterceptor
+}
+
+// Use registers the interceptor for all requests performed by Request and the functions built upon it. The
+// interceptors are applied in the order of their registration, from the goroutine of the request.
+func Use(interceptor RequestInterceptor) {
+	interceptors.mutex.Lock()
+	defer interceptors.mutex.Unlock()
+
+	interceptors.list = append(interceptors.list, interceptor)
+}
+
+// intercept applies all interceptors until the first error.
+func intercept(req *http.Request) error {
+	interceptors.mutex.Lock()
+	list := interceptors.list
+	interceptors.mutex.Unlock()
+
+	for _, interceptor := range list {
+		if err := interceptor(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
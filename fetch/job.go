@@ -0,0 +1,123 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SubmitAndPoll performs the request, which starts an asynchronous job, e.g. a POST. If the server answers with
+// 202 Accepted and a Location header, the status url is polled with GET requests every interval, or after the delay
+// of a Retry-After header, as long as it answers with 202 Accepted. Any other successful response (2xx) is the final
+// one and passed to the callback, which includes the result of a 303 See Other, because the client follows it. A
+// 202 Accepted without Location is final as well, unless the start request is a GET, which is then polled itself.
+// A status code other than 2xx is passed as *HTTPError. The start and all status requests share the deadline of
+// maxWait, thus if the job is not finished in time, even due to a hanging request, the callback receives a
+// *TimeoutError, which matches ErrTimeout and context.DeadlineExceeded. A nil client is treated as the DefaultClient.
+func SubmitAndPoll(startReq *http.Request, client *http.Client, interval time.Duration, maxWait time.Duration,
+	f func(final *http.Response, err error)) {
+	if client == nil {
+		client = DefaultClient()
+	}
+
+	startReq, finish := detach(startReq)
+	deadline := time.Now().Add(maxWait)
+
+	ctx, cancel := context.WithDeadline(startReq.Context(), deadline)
+	startReq = startReq.WithContext(ctx)
+
+	done := func(res *http.Response, err error) {
+		defer finish()
+		defer cancel()
+
+		f(res, err)
+	}
+
+	Request(client, startReq, func(res *http.Response, err error) {
+		pollJob(client, startReq, res, err, interval, deadline, done)
+	})
+}
+
+// pollJob evaluates the response of the start or a status request and schedules the next status request.
+func pollJob(client *http.Client, req *http.Request, res *http.Response, err error, interval time.Duration,
+	deadline time.Time, f func(final *http.Response, err error)) {
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	if !isSuccess(res.StatusCode) {
+		f(nil, newHTTPError(res))
+
+		return
+	}
+
+	location := res.Header.Get("Location")
+	if res.StatusCode != http.StatusAccepted || (location == "" && req.Method != http.MethodGet) {
+		f(res, nil)
+
+		return
+	}
+
+	statusURL := req.URL
+	if location != "" {
+		u, err := req.URL.Parse(location)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		statusURL = u
+	}
+
+	delay := retryAfter(res, interval)
+	_ = Drain(res)
+
+	if time.Now().Add(delay).After(deadline) {
+		f(nil, &TimeoutError{Err: context.DeadlineExceeded})
+
+		return
+	}
+
+	ctx := strip(req).Context()
+
+	go func() {
+		defer GlobalPanicHandler()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		// a cancelled context is delivered by the status request without waiting
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+
+		statusReq, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL.String(), nil)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		Request(client, statusReq, func(res *http.Response, err error) {
+			pollJob(client, statusReq, res, err, interval, deadline, f)
+		})
+	}()
+}
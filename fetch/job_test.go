@@ -0,0 +1,113 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSubmitAndPoll(t *testing.T) {
+	tests := []struct {
+		name        string
+		pending     int
+		hang        bool
+		retryAfter  string
+		wantStatus  int
+		wantTimeout bool
+	}{
+		{name: "finished after polling", pending: 2, wantStatus: http.StatusOK},
+		{name: "finished immediately", wantStatus: http.StatusOK},
+		{name: "hanging status request", pending: 1, hang: true, wantTimeout: true},
+		{name: "retry after exceeds the wait", pending: 1, retryAfter: "3600", wantTimeout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+
+			client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodPost {
+					if tt.pending == 0 {
+						return MockResponse(http.StatusOK, "done"), nil
+					}
+
+					res := MockResponse(http.StatusAccepted, "")
+					res.Header.Set("Location", "/jobs/1")
+					res.Header.Set("Retry-After", tt.retryAfter)
+
+					return res, nil
+				}
+
+				if tt.hang {
+					<-req.Context().Done()
+
+					return nil, req.Context().Err()
+				}
+
+				if polls++; polls < tt.pending {
+					return MockResponse(http.StatusAccepted, ""), nil
+				}
+
+				return MockResponse(http.StatusOK, "done"), nil
+			})
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://localhost/jobs", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			type result struct {
+				status int
+				err    error
+			}
+
+			results := make(chan result, 1)
+
+			SubmitAndPoll(req, client, time.Millisecond, 100*time.Millisecond, func(res *http.Response, err error) {
+				if err != nil {
+					results <- result{err: err}
+
+					return
+				}
+
+				results <- result{status: res.StatusCode}
+			})
+
+			var got result
+			select {
+			case got = <-results:
+			case <-time.After(time.Second):
+				t.Fatal("callback not invoked within the maximum wait time")
+			}
+
+			if tt.wantTimeout {
+				var timeoutErr *TimeoutError
+				if !errors.As(got.err, &timeoutErr) || !errors.Is(got.err, context.DeadlineExceeded) {
+					t.Fatalf("expected a *TimeoutError, got %v", got.err)
+				}
+
+				return
+			}
+
+			if got.err != nil || got.status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d and %v", tt.wantStatus, got.status, got.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// quoteEscaper escapes the values of a Content-Disposition header, like the mime/multipart package does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"") //nolint:gochecknoglobals
+
+// MultipartBody is a builder for a multipart/form-data body with field values and file parts. The parts are
+// streamed in the order of their declaration, when the body is sent, thus large file readers are not buffered.
+type MultipartBody struct {
+	boundary string
+	parts    []multipartPart
+}
+
+// multipartPart is either a field or a file.
+type multipartPart struct {
+	name        string
+	filename    string
+	contentType string
+	value       io.Reader
+}
+
+// NewMultipartBody creates a new empty multipart body with a random boundary.
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{boundary: multipart.NewWriter(ioutil.Discard).Boundary()}
+}
+
+// AddField appends a field with the given value.
+func (m *MultipartBody) AddField(name, value string) *MultipartBody {
+	m.parts = append(m.parts, multipartPart{name: name, value: strings.NewReader(value)})
+
+	return m
+}
+
+// AddFile appends a file part, which is read from r when sending. If contentType is empty,
+// application/octet-stream is used.
+func (m *MultipartBody) AddFile(name, filename, contentType string, r io.Reader) *MultipartBody {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	m.parts = append(m.parts, multipartPart{name: name, filename: filename, contentType: contentType, value: r})
+
+	return m
+}
+
+// ContentType returns the multipart/form-data Content-Type including the boundary.
+func (m *MultipartBody) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// Reader returns a stream of the encoded body. Each part is written, while the stream is read, so the body
+// can only be read once. Closing the reader stops the encoding.
+func (m *MultipartBody) Reader() io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer GlobalPanicHandler()
+
+		pw.CloseWithError(m.writeTo(pw))
+	}()
+
+	return pr
+}
+
+// writeTo encodes all parts into w.
+func (m *MultipartBody) writeTo(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range m.parts {
+		header := textproto.MIMEHeader{}
+
+		if part.filename == "" {
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(part.name)))
+		} else {
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+				quoteEscaper.Replace(part.name), quoteEscaper.Replace(part.filename)))
+			header.Set("Content-Type", part.contentType)
+		}
+
+		pw, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(pw, part.value); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+// PostMultipart performs a http POST with the given multipart body and its Content-Type including the boundary.
+func PostMultipart(url string, body *MultipartBody, f func(res *http.Response, err error)) {
+	r := body.Reader()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, r)
+	if err != nil {
+		_ = r.Close()
+
+		f(nil, err)
+
+		return
+	}
+
+	req.Header.Set("Content-Type", body.ContentType())
+
+	Request(DefaultClient(), req, f)
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// An OfflineQueue sends requests one after another in the order of their enqueueing. A request which fails, because
+// the browser is offline, is kept at the head of the queue and replayed as soon as the browser reports to be online
+// again, so that writes made while offline are not lost. The queue lives in memory only, so that pending requests are
+// lost on a reload.
+type OfflineQueue struct {
+	client      *http.Client
+	mutex       sync.Mutex
+	pending     []queuedRequest
+	sending     bool
+	unsubscribe func()
+}
+
+// queuedRequest is a detached request of the queue and its callback.
+type queuedRequest struct {
+	req    *http.Request
+	finish func()
+	f      func(res *http.Response, err error)
+}
+
+// NewOfflineQueue creates a queue which sends its requests using the given client and listens for connectivity
+// changes, see OnConnectivityChange. Invoke Close, if the queue is not required anymore.
+func NewOfflineQueue(client *http.Client) *OfflineQueue {
+	q := &OfflineQueue{client: client}
+	q.unsubscribe = OnConnectivityChange(func(online bool) {
+		if online {
+			q.flush()
+		}
+	})
+
+	return q
+}
+
+// Enqueue appends the request to the queue. The callback is invoked, when the request has been sent and did not fail
+// due to being offline, which may be much later. The body of the request is buffered, so that it can be replayed.
+func (q *OfflineQueue) Enqueue(req *http.Request, f func(res *http.Response, err error)) {
+	req, finish := detach(req)
+
+	if err := rewindable(req); err != nil {
+		finish()
+		f(nil, err)
+
+		return
+	}
+
+	q.mutex.Lock()
+	q.pending = append(q.pending, queuedRequest{req: req, finish: finish, f: f})
+	q.mutex.Unlock()
+
+	q.flush()
+}
+
+// Len returns the amount of requests which have not been completed yet, including the one in flight.
+func (q *OfflineQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.pending)
+}
+
+// Close removes the connectivity listeners. Pending requests are kept but not sent automatically anymore.
+func (q *OfflineQueue) Close() {
+	q.unsubscribe()
+}
+
+// flush sends the head of the queue, if no other request is in flight and the browser is online.
+func (q *OfflineQueue) flush() {
+	q.mutex.Lock()
+	if q.sending || len(q.pending) == 0 || !IsOnline() {
+		q.mutex.Unlock()
+
+		return
+	}
+
+	q.sending = true
+	head := q.pending[0]
+	q.mutex.Unlock()
+
+	attemptReq, err := rewind(head.req)
+	if err != nil {
+		q.complete(head, nil, err)
+
+		return
+	}
+
+	Request(q.client, attemptReq, func(res *http.Response, err error) {
+		if offline(err) {
+			q.mutex.Lock()
+			q.sending = false
+			q.mutex.Unlock()
+
+			// the online event may have been missed while the request was in flight
+			if IsOnline() {
+				go q.flush()
+			}
+
+			return
+		}
+
+		q.complete(head, res, err)
+	})
+}
+
+// complete removes the head of the queue, invokes its callback and continues with the next request.
+func (q *OfflineQueue) complete(head queuedRequest, res *http.Response, err error) {
+	q.mutex.Lock()
+	q.pending = q.pending[1:]
+	q.sending = false
+	q.mutex.Unlock()
+
+	defer q.flush()
+	defer head.finish()
+
+	head.f(res, err)
+}
+
+// offline decides if the error has been caused by missing connectivity.
+func offline(err error) bool {
+	if errors.Is(err, ErrOffline) {
+		return true
+	}
+
+	var netErr *NetworkError
+
+	return errors.As(err, &netErr) && !IsOnline()
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import "syscall/js"
+
+// IsOnline returns the value of navigator.onLine. Note, that true does not guarantee, that any host is actually
+// reachable. If the navigator is not available, true is assumed.
+func IsOnline() bool {
+	navigator := js.Global().Get("navigator")
+	if navigator.IsUndefined() || navigator.Get("onLine").IsUndefined() {
+		return true
+	}
+
+	return navigator.Get("onLine").Bool()
+}
+
+// OnConnectivityChange registers the given callback for the online and offline events of the browser. The callback
+// is invoked from a new goroutine, like the request callbacks. Use the returned function to remove the listeners.
+func OnConnectivityChange(f func(online bool)) (unsubscribe func()) {
+	global := js.Global()
+
+	listener := func(online bool) js.Func {
+		return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			go func() {
+				defer GlobalPanicHandler()
+
+				f(online)
+			}()
+
+			return nil
+		})
+	}
+
+	onOnline := listener(true)
+	onOffline := listener(false)
+
+	global.Call("addEventListener", "online", onOnline)
+	global.Call("addEventListener", "offline", onOffline)
+
+	return func() {
+		global.Call("removeEventListener", "online", onOnline)
+		global.Call("removeEventListener", "offline", onOffline)
+		onOnline.Release()
+		onOffline.Release()
+	}
+}
@@ -0,0 +1,430 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// An Option configures a request, which is created by Do or NewRequest.
+type Option func(b *builder) error
+
+// builder collects the configuration of all options to create a request.
+type builder struct {
+	method string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+	ctx    context.Context //nolint:containedctx
+	gzip   bool
+	// scopes derive the final context of the request. Their release functions are invoked, after the request
+	// has been completed.
+	scopes []func(ctx context.Context) (context.Context, func())
+	// wrap contains the decorators of the callback, see config.
+	wrap []wrapper
+	// rewindable buffers the body, if required, so that the request can be replayed.
+	rewindable bool
+	// checkRedirect replaces the redirect policy of the client, see config.
+	checkRedirect func(req *http.Request, via []*http.Request) error
+	// coalesceKey replaces the key of a SingleFlightClient, see config.
+	coalesceKey func(req *http.Request) string
+}
+
+// callback is the signature of all request callbacks.
+type callback = func(res *http.Response, err error)
+
+// wrapper decorates the callback of a request performed by the given client, e.g. to replay it.
+type wrapper func(client *http.Client, req *http.Request, next callback) callback
+
+// configKey is the context key of the *config of a request.
+type configKey struct{}
+
+// config is attached to the context of a request created by NewRequest and is evaluated by Request.
+type config struct {
+	release       []func()
+	wrap          []wrapper
+	checkRedirect func(req *http.Request, via []*http.Request) error
+	coalesceKey   func(req *http.Request) string
+	// onResponse are invoked and removed by roundTrip, as soon as the response headers have been received, so that
+	// each attempt of a Retry registers its own ones, see WithTrace.
+	onResponse []func()
+}
+
+// configOf returns the config of the request or nil.
+func configOf(req *http.Request) *config {
+	cfg, _ := req.Context().Value(configKey{}).(*config)
+
+	return cfg
+}
+
+// decorate applies the wrappers to the callback, so that the first wrapper is the outermost one. The resources are
+// released, after the given callback has been invoked. It is safe to call it on a nil config.
+func (c *config) decorate(client *http.Client, req *http.Request, f callback) callback {
+	if c == nil {
+		return f
+	}
+
+	decorated := func(res *http.Response, err error) {
+		defer c.finish()
+
+		f(res, err)
+	}
+
+	for i := len(c.wrap) - 1; i >= 0; i-- {
+		decorated = c.wrap[i](client, req, decorated)
+	}
+
+	return decorated
+}
+
+// responded invokes and removes the onResponse functions. It is safe to call it on a nil config.
+func (c *config) responded() {
+	if c == nil {
+		return
+	}
+
+	hooks := c.onResponse
+	c.onResponse = nil
+
+	for _, f := range hooks {
+		f()
+	}
+}
+
+// finish releases all resources of the request. It is safe to call it on a nil config.
+func (c *config) finish() {
+	if c == nil {
+		return
+	}
+
+	for _, release := range c.release {
+		release()
+	}
+
+	c.release = nil
+}
+
+// detach returns a copy of the request, whose config keeps the wrappers but not the resources, and a function to
+// finish its original config. This is used to perform the same logical request multiple times, e.g. by Retry.
+func detach(req *http.Request) (*http.Request, func()) {
+	cfg := configOf(req)
+	if cfg == nil {
+		return req, func() {}
+	}
+
+	detached := &config{wrap: cfg.wrap, checkRedirect: cfg.checkRedirect, coalesceKey: cfg.coalesceKey}
+
+	return req.WithContext(context.WithValue(req.Context(), configKey{}, detached)), cfg.finish
+}
+
+// strip returns a copy of the request without any config, so that Request performs it as is.
+func strip(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), configKey{}, nil))
+}
+
+// WithMethod sets the http method, which is GET by default.
+func WithMethod(method string) Option {
+	return func(b *builder) error {
+		b.method = method
+
+		return nil
+	}
+}
+
+// WithHeader sets the header key to the given value, replacing any existing values.
+func WithHeader(key, value string) Option {
+	return func(b *builder) error {
+		b.header.Set(key, value)
+
+		return nil
+	}
+}
+
+// WithHeaders sets all given headers, replacing any existing values. See also WithHeader.
+func WithHeaders(headers map[string]string) Option {
+	return func(b *builder) error {
+		for key, value := range headers {
+			b.header.Set(key, value)
+		}
+
+		return nil
+	}
+}
+
+// WithAccept sets the Accept header to the given media types in the order of their preference, e.g.
+// "application/json, text/plain". A type may carry an explicit weight, like "text/*;q=0.5". A malformed type is
+// rejected with ErrInvalidOption.
+func WithAccept(types ...string) Option {
+	return func(b *builder) error {
+		for _, t := range types {
+			if _, _, err := mime.ParseMediaType(t); err != nil {
+				return fmt.Errorf("invalid media type '%s' for Accept: %w", t, ErrInvalidOption)
+			}
+		}
+
+		b.header.Set("Accept", strings.Join(types, ", "))
+
+		return nil
+	}
+}
+
+// WithContentType sets the Content-Type header, which describes the body of the request. A malformed type is
+// rejected with ErrInvalidOption.
+func WithContentType(contentType string) Option {
+	return func(b *builder) error {
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return fmt.Errorf("invalid media type '%s' for Content-Type: %w", contentType, ErrInvalidOption)
+		}
+
+		b.header.Set("Content-Type", contentType)
+
+		return nil
+	}
+}
+
+// WithQuery adds the key and value to the query of the url. Adding the same key multiple times, results in multiple
+// values. Existing parameters of the url are kept.
+func WithQuery(key, value string) Option {
+	return func(b *builder) error {
+		b.query.Add(key, value)
+
+		return nil
+	}
+}
+
+// WithQueryParams adds all keys and values of the map to the query of the url, see also WithQuery.
+func WithQueryParams(params map[string]string) Option {
+	return func(b *builder) error {
+		for key, value := range params {
+			b.query.Add(key, value)
+		}
+
+		return nil
+	}
+}
+
+// WithQueryValues adds all values of the given url.Values to the query of the url. Repeated keys result in multiple
+// values, see also WithQuery.
+func WithQueryValues(values url.Values) Option {
+	return func(b *builder) error {
+		for key, vals := range values {
+			for _, value := range vals {
+				b.query.Add(key, value)
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithAutoDrain drains the remainder of the response body, after the callback has returned or panicked, see Drain.
+// Note, that an endless stream, like text/event-stream, is drained forever.
+func WithAutoDrain() Option {
+	return func(b *builder) error {
+		b.wrap = append(b.wrap, func(client *http.Client, req *http.Request, next callback) callback {
+			return func(res *http.Response, err error) {
+				defer Drain(res) //nolint:errcheck
+
+				next(res, err)
+			}
+		})
+
+		return nil
+	}
+}
+
+// WithBody sets the body of the request.
+func WithBody(body io.Reader) Option {
+	return func(b *builder) error {
+		b.body = body
+
+		return nil
+	}
+}
+
+// WithContext sets the context of the request, which is context.Background() by default.
+func WithContext(ctx context.Context) Option {
+	return func(b *builder) error {
+		b.ctx = ctx
+
+		return nil
+	}
+}
+
+// WithBearer sets the Authorization header to the given bearer token. An empty token causes ErrInvalidCredentials.
+func WithBearer(token string) Option {
+	return func(b *builder) error {
+		if token == "" {
+			return fmt.Errorf("empty bearer token: %w", ErrInvalidCredentials)
+		}
+
+		b.header.Set("Authorization", "Bearer "+token)
+
+		return nil
+	}
+}
+
+// WithBasicAuth sets the Authorization header using the basic authentication scheme. An empty user or a user
+// containing a colon (see RFC 7617) causes ErrInvalidCredentials.
+func WithBasicAuth(user, password string) Option {
+	return func(b *builder) error {
+		if user == "" || strings.Contains(user, ":") {
+			return fmt.Errorf("invalid basic auth user '%s': %w", user, ErrInvalidCredentials)
+		}
+
+		b.header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+
+		return nil
+	}
+}
+
+// WithGzip compresses the body using gzip and sets the Content-Encoding header accordingly. The compressed body is
+// buffered in memory. If a Content-Encoding has already been set, the body is considered to be encoded already and
+// is left untouched. Note, that the server must support compressed request bodies.
+func WithGzip() Option {
+	return func(b *builder) error {
+		b.gzip = true
+
+		return nil
+	}
+}
+
+// NewRequest creates a new request from the given url and options.
+func NewRequest(rawURL string, opts ...Option) (*http.Request, error) {
+	b := &builder{
+		method: http.MethodGet,
+		header: http.Header{},
+		query:  url.Values{},
+		ctx:    context.Background(),
+	}
+
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.build(rawURL)
+}
+
+// Do creates a request from the given url and options and performs it using the DefaultClient. Any error
+// of the options is passed to the callback, without issuing any request. Example:
+//
+//	Do("http://...", AsText(func(res string, err error) {
+//	    // do something with res
+//	}), WithMethod(http.MethodPut), WithHeader("X-Custom", "value"), WithBody(strings.NewReader("hello")))
+func Do(url string, f func(res *http.Response, err error), opts ...Option) {
+	req, err := NewRequest(url, opts...)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	Request(DefaultClient(), req, f)
+}
+
+// build creates the actual request.
+func (b *builder) build(rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.query) > 0 {
+		q := u.Query()
+
+		for key, values := range b.query {
+			for _, value := range values {
+				q.Add(key, value)
+			}
+		}
+
+		u.RawQuery = q.Encode()
+	}
+
+	if b.gzip && b.body != nil && b.header.Get("Content-Encoding") == "" {
+		if err := b.compress(); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := b.ctx
+	cfg := &config{}
+
+	for _, scope := range b.scopes {
+		var release func()
+
+		ctx, release = scope(ctx)
+		cfg.release = append(cfg.release, release)
+	}
+
+	cfg.wrap = b.wrap
+	cfg.checkRedirect = b.checkRedirect
+	cfg.coalesceKey = b.coalesceKey
+
+	if len(cfg.release) > 0 || len(cfg.wrap) > 0 || cfg.checkRedirect != nil || cfg.coalesceKey != nil {
+		ctx = context.WithValue(ctx, configKey{}, cfg)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, b.method, u.String(), b.body)
+	if err != nil {
+		cfg.finish()
+
+		return nil, err
+	}
+
+	if b.rewindable {
+		if err := rewindable(req); err != nil {
+			cfg.finish()
+
+			return nil, err
+		}
+	}
+
+	for key, values := range b.header {
+		req.Header[key] = values
+	}
+
+	return req, nil
+}
+
+// compress replaces the body with its gzip compressed bytes.
+func (b *builder) compress() error {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := io.Copy(w, b.body); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	b.body = &buf
+	b.header.Set("Content-Encoding", "gzip")
+
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNewRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		opts       []Option
+		wantMethod string
+		wantURL    string
+		wantHeader http.Header
+	}{
+		{
+			name:       "defaults",
+			url:        "https://my.domain/users",
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/users",
+		},
+		{
+			name:       "method and header",
+			url:        "https://my.domain/users",
+			opts:       []Option{WithMethod(http.MethodPut), WithHeader("X-A", "1")},
+			wantMethod: http.MethodPut,
+			wantURL:    "https://my.domain/users",
+			wantHeader: http.Header{"X-A": {"1"}},
+		},
+		{
+			name:       "headers",
+			url:        "https://my.domain/users",
+			opts:       []Option{WithHeader("X-A", "1"), WithHeaders(map[string]string{"X-A": "2", "X-B": "3"})},
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/users",
+			wantHeader: http.Header{"X-A": {"2"}, "X-B": {"3"}},
+		},
+		{
+			name:       "query keeps existing parameters",
+			url:        "https://my.domain/users?page=1",
+			opts:       []Option{WithQuery("q", "a b"), WithQuery("tag", "x"), WithQuery("tag", "y")},
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/users?page=1&q=a+b&tag=x&tag=y",
+		},
+		{
+			name: "query params and values",
+			url:  "https://my.domain/users?page=1",
+			opts: []Option{
+				WithQueryParams(map[string]string{"sort": "name"}),
+				WithQueryValues(url.Values{"tag": {"x", "y"}}),
+			},
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/users?page=1&sort=name&tag=x&tag=y",
+		},
+		{
+			name:       "accept and content type",
+			url:        "https://my.domain/",
+			opts:       []Option{WithAccept("application/json", "text/*;q=0.5"), WithContentType("text/plain")},
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/",
+			wantHeader: http.Header{"Accept": {"application/json, text/*;q=0.5"}, "Content-Type": {"text/plain"}},
+		},
+		{
+			name:       "bearer",
+			url:        "https://my.domain/",
+			opts:       []Option{WithBearer("token")},
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/",
+			wantHeader: http.Header{"Authorization": {"Bearer token"}},
+		},
+		{
+			name:       "basic auth",
+			url:        "https://my.domain/",
+			opts:       []Option{WithBasicAuth("user", "secret")},
+			wantMethod: http.MethodGet,
+			wantURL:    "https://my.domain/",
+			wantHeader: http.Header{"Authorization": {"Basic dXNlcjpzZWNyZXQ="}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewRequest(tt.url, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if req.Method != tt.wantMethod {
+				t.Fatalf("expected method %s, got %s", tt.wantMethod, req.Method)
+			}
+
+			if got := req.URL.String(); got != tt.wantURL {
+				t.Fatalf("expected url %s, got %s", tt.wantURL, got)
+			}
+
+			for key := range tt.wantHeader {
+				if got := req.Header.Get(key); got != tt.wantHeader.Get(key) {
+					t.Fatalf("expected header %s '%s', got '%s'", key, tt.wantHeader.Get(key), got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRequestInvalidOption(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+		want error
+	}{
+		{name: "empty bearer", opt: WithBearer(""), want: ErrInvalidCredentials},
+		{name: "empty user", opt: WithBasicAuth("", "secret"), want: ErrInvalidCredentials},
+		{name: "user with colon", opt: WithBasicAuth("a:b", "secret"), want: ErrInvalidCredentials},
+		{name: "malformed accept", opt: WithAccept("application/json", "/"), want: ErrInvalidOption},
+		{name: "malformed content type", opt: WithContentType("text/"), want: ErrInvalidOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRequest("https://my.domain/", tt.opt); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewRequestGzip(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		wantEncoding string
+		wantBody     string
+	}{
+		{name: "compressed", opts: []Option{WithGzip()}, wantEncoding: "gzip", wantBody: "hello"},
+		{name: "already encoded", opts: []Option{WithGzip(), WithHeader("Content-Encoding", "br")},
+			wantEncoding: "br", wantBody: "hello"},
+		{name: "uncompressed", wantBody: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithBody(strings.NewReader("hello"))}, tt.opts...)
+
+			req, err := NewRequest("https://my.domain/", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := req.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("expected Content-Encoding '%s', got '%s'", tt.wantEncoding, got)
+			}
+
+			buf, err := io.ReadAll(req.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantEncoding == "gzip" {
+				r, err := gzip.NewReader(bytes.NewReader(buf))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if buf, err = io.ReadAll(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := string(buf); got != tt.wantBody {
+				t.Fatalf("expected body %q, got %q", tt.wantBody, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+)
+
+// MaxPages limits the amount of pages fetched by GetAllPages, to protect against endless or cyclic pagination.
+var MaxPages = 1000 //nolint:gochecknoglobals
+
+// GetAllPages performs a GET request for the url and follows the rel="next" links of the Link header (RFC 8288) of
+// each response, until there is no next link. Each response is passed to onPage, whose body is only valid until it
+// returns. If onPage returns an error, the pagination stops and done receives that error. A response with a status
+// code other than 2xx is reported to done as *HTTPError and more than MaxPages pages as ErrTooManyPages. A nil client
+// is treated as the DefaultClient.
+func GetAllPages(url string, client *http.Client, onPage func(res *http.Response) error, done func(err error)) {
+	if client == nil {
+		client = DefaultClient()
+	}
+
+	getPage(url, client, 1, onPage, done)
+}
+
+// getPage fetches the given page and continues with the next one.
+func getPage(url string, client *http.Client, page int, onPage func(res *http.Response) error, done func(err error)) {
+	if page > MaxPages {
+		done(fmt.Errorf("%w: more than %d pages", ErrTooManyPages, MaxPages))
+
+		return
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		done(err)
+
+		return
+	}
+
+	Request(client, req, func(res *http.Response, err error) {
+		if err != nil {
+			done(err)
+
+			return
+		}
+
+		if !isSuccess(res.StatusCode) {
+			done(newHTTPError(res))
+
+			return
+		}
+
+		if err := onPage(res); err != nil {
+			done(err)
+
+			return
+		}
+
+		next := nextLink(res)
+		if next == "" {
+			done(nil)
+
+			return
+		}
+
+		getPage(next, client, page+1, onPage, done)
+	})
+}
+
+// Pages returns an iterator over the pages of GetAllPages, which fetches the next page only after the loop body of
+// the current one has completed. The body of each response is only valid within its iteration. A failure is yielded
+// as the last element, e.g. a *HTTPError for a status code other than 2xx. Breaking the loop stops the pagination.
+// The iteration blocks while waiting for a page, so it must not be used from the UI or DOM Thread.
+//
+//	for res, err := range fetch.Pages("https://my.domain/items", nil) {
+//		if err != nil {
+//			return err
+//		}
+//
+//		// read res.Body
+//	}
+func Pages(url string, client *http.Client) iter.Seq2[*http.Response, error] {
+	return func(yield func(*http.Response, error) bool) {
+		if client == nil {
+			client = DefaultClient()
+		}
+
+		for page := 1; url != ""; page++ {
+			if page > MaxPages {
+				yield(nil, fmt.Errorf("%w: more than %d pages", ErrTooManyPages, MaxPages))
+
+				return
+			}
+
+			next, ok := yieldPage(client, url, yield)
+			if !ok {
+				return
+			}
+
+			url = next
+		}
+	}
+}
+
+// yieldPage fetches the page and yields it, while the callback of the request waits, so that the body stays valid.
+// It returns the url of the next page and false, if the iteration has to stop.
+func yieldPage(client *http.Client, url string, yield func(*http.Response, error) bool) (next string, ok bool) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		yield(nil, err)
+
+		return "", false
+	}
+
+	results := make(chan Result, 1)
+	yielded := make(chan struct{})
+
+	Request(client, req, func(res *http.Response, err error) {
+		if err == nil && !isSuccess(res.StatusCode) {
+			res, err = nil, newHTTPError(res)
+		}
+
+		results <- Result{Res: res, Err: err}
+		<-yielded
+	})
+
+	result := <-results
+	defer close(yielded)
+
+	if result.Err != nil {
+		yield(nil, result.Err)
+
+		return "", false
+	}
+
+	next = nextLink(result.Res)
+
+	return next, yield(result.Res, nil)
+}
+
+// nextLink returns the absolute url of the rel="next" link of the response or the empty string.
+func nextLink(res *http.Response) string {
+	for _, header := range res.Header.Values("Link") {
+		for _, link := range splitLinks(header) {
+			target, params := parseLink(link)
+			if target == "" {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.ToLower(params["rel"])) {
+				if rel != "next" {
+					continue
+				}
+
+				u, err := res.Request.URL.Parse(target)
+				if err != nil {
+					return ""
+				}
+
+				return u.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+// splitLinks splits a Link header value at the commas which are neither within the <> of a target nor within
+// a quoted parameter.
+func splitLinks(header string) []string {
+	var (
+		links    []string
+		start    int
+		inTarget bool
+		inQuote  bool
+	)
+
+	for i, r := range header {
+		switch {
+		case inQuote:
+			inQuote = r != '"'
+		case inTarget:
+			inTarget = r != '>'
+		case r == '"':
+			inQuote = true
+		case r == '<':
+			inTarget = true
+		case r == ',':
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(links, header[start:])
+}
+
+// parseLink parses a single link like <https://example.com/?page=2>; rel="next" into its target and parameters.
+func parseLink(link string) (target string, params map[string]string) {
+	link = strings.TrimSpace(link)
+	if !strings.HasPrefix(link, "<") {
+		return "", nil
+	}
+
+	end := strings.Index(link, ">")
+	if end < 0 {
+		return "", nil
+	}
+
+	params = map[string]string{}
+
+	for _, param := range strings.Split(link[end+1:], ";") {
+		key, value := param, ""
+		if eq := strings.Index(param, "="); eq >= 0 {
+			key, value = param[:eq], param[eq+1:]
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "" {
+			params[key] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return link[1:end], params
+}
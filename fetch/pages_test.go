@@ -0,0 +1,168 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []string
+		want  string
+	}{
+		{name: "none", want: ""},
+		{name: "absolute", links: []string{`<https://my.domain/items?page=2>; rel="next"`},
+			want: "https://my.domain/items?page=2"},
+		{name: "relative", links: []string{`</items?page=2>; rel=next`}, want: "https://my.domain/items?page=2"},
+		{name: "among others", links: []string{`<https://my.domain/items?page=1>; rel="prev", ` +
+			`<https://my.domain/items?page=3>; rel="next", <https://my.domain/items?page=9>; rel="last"`},
+			want: "https://my.domain/items?page=3"},
+		{name: "multiple relations", links: []string{`<https://my.domain/b>; rel="last NEXT"`},
+			want: "https://my.domain/b"},
+		{name: "comma within target and parameter", links: []string{`<https://my.domain/a,b>; title="x, y"; rel="next"`},
+			want: "https://my.domain/a,b"},
+		{name: "separate headers", links: []string{`<https://my.domain/a>; rel="prev"`, `<https://my.domain/b>; rel="next"`},
+			want: "https://my.domain/b"},
+		{name: "malformed", links: []string{`https://my.domain/b; rel="next"`}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://my.domain/items", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := MockResponse(http.StatusOK, "")
+			res.Request = req
+
+			for _, link := range tt.links {
+				res.Header.Add("Link", link)
+			}
+
+			if got := nextLink(res); got != tt.want {
+				t.Fatalf("expected '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPages(t *testing.T) {
+	tests := []struct {
+		name        string
+		pages       int
+		failAt      int
+		breakAt     int
+		maxPages    int
+		wantBodies  []string
+		wantErr     error
+		wantStatus  int
+		wantFetched int
+	}{
+		{name: "all pages", pages: 3, wantBodies: []string{"1", "2", "3"}, wantFetched: 3},
+		{name: "server error", pages: 3, failAt: 2, wantBodies: []string{"1"},
+			wantStatus: http.StatusInternalServerError, wantFetched: 2},
+		{name: "break", pages: 3, breakAt: 2, wantBodies: []string{"1", "2"}, wantFetched: 2},
+		{name: "too many pages", pages: 3, maxPages: 2, wantBodies: []string{"1", "2"}, wantErr: ErrTooManyPages,
+			wantFetched: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.maxPages > 0 {
+				maxPages := MaxPages
+				MaxPages = tt.maxPages
+				t.Cleanup(func() { MaxPages = maxPages })
+			}
+
+			var fetched int
+
+			client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+				fetched++
+
+				page, err := strconv.Atoi(req.URL.Query().Get("page"))
+				if err != nil {
+					page = 1
+				}
+
+				if page == tt.failAt {
+					return MockResponse(http.StatusInternalServerError, ""), nil
+				}
+
+				res := MockResponse(http.StatusOK, strconv.Itoa(page))
+				if page < tt.pages {
+					res.Header.Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+				}
+
+				return res, nil
+			})
+
+			var (
+				bodies []string
+				gotErr error
+			)
+
+			for res, err := range Pages("https://my.domain/items", client) {
+				if err != nil {
+					gotErr = err
+
+					break
+				}
+
+				buf, err := io.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				bodies = append(bodies, string(buf))
+
+				if len(bodies) == tt.breakAt {
+					break
+				}
+			}
+
+			if !reflect.DeepEqual(bodies, tt.wantBodies) {
+				t.Fatalf("expected %v, got %v", tt.wantBodies, bodies)
+			}
+
+			var httpErr *HTTPError
+
+			switch {
+			case tt.wantStatus != 0:
+				if !errors.As(gotErr, &httpErr) || httpErr.StatusCode != tt.wantStatus {
+					t.Fatalf("expected a *HTTPError with status %d, got %v", tt.wantStatus, gotErr)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(gotErr, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, gotErr)
+				}
+			case gotErr != nil:
+				t.Fatalf("unexpected error %v", gotErr)
+			}
+
+			if fetched != tt.wantFetched {
+				t.Fatalf("expected %d fetched pages, got %d", tt.wantFetched, fetched)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Poll performs a http GET immediately and then repeatedly after each interval, until the returned stop function is
+// called. If a request is still in flight when the next interval elapses, that tick is skipped, so there is at most
+// one request at a time and the callbacks never overlap. After stop has been called, an in-flight request is
+// aborted and the callback is not invoked anymore. An interval of zero or less is rejected with ErrInvalidOption.
+func Poll(url string, interval time.Duration, f func(res *http.Response, err error)) (stop func()) {
+	if interval <= 0 {
+		f(nil, fmt.Errorf("invalid poll interval '%v': %w", interval, ErrInvalidOption))
+
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		cancel()
+		f(nil, err)
+
+		return func() {}
+	}
+
+	var (
+		mutex    sync.Mutex
+		inFlight bool
+	)
+
+	poll := func() {
+		mutex.Lock()
+		if inFlight {
+			mutex.Unlock()
+
+			return
+		}
+
+		inFlight = true
+		mutex.Unlock()
+
+		Request(DefaultClient(), req.Clone(ctx), func(res *http.Response, err error) {
+			defer func() {
+				mutex.Lock()
+				inFlight = false
+				mutex.Unlock()
+			}()
+
+			if ctx.Err() != nil {
+				return // stopped
+			}
+
+			f(res, err)
+		})
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		poll()
+
+		for {
+			select {
+			case <-ticker.C:
+				poll()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cancel
+}
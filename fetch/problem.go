@@ -0,0 +1,113 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// Problem contains the details of a failed request as defined by RFC 7807 for application/problem+json.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions contains all other members of the problem object.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// Error returns the title and the detail of the problem.
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("fetch: problem: %d %s", p.Status, p.Title)
+	}
+
+	return fmt.Sprintf("fetch: problem: %d %s: %s", p.Status, p.Title, p.Detail)
+}
+
+// AsProblem is a middleware for an async http response, which decodes a failed response (other than 2xx) with the
+// Content-Type application/problem+json into a Problem. A missing status of the problem is taken from the
+// response. Any other failed response is passed as *HTTPError and a successful response is drained, so that the
+// callback receives neither a problem nor an error.
+func AsProblem(f func(problem *Problem, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		if isSuccess(res.StatusCode) {
+			f(nil, Drain(res))
+
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+		if mediaType != "application/problem+json" {
+			f(nil, newHTTPError(res))
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		problem, err := parseProblem(buf)
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		if problem.Status == 0 {
+			problem.Status = res.StatusCode
+		}
+
+		f(problem, nil)
+	}
+}
+
+// parseProblem decodes the problem and collects its extension members.
+func parseProblem(buf []byte) (*Problem, error) {
+	problem := &Problem{}
+	if err := json.Unmarshal(buf, problem); err != nil {
+		return nil, err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(buf, &members); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+
+	if len(members) > 0 {
+		problem.Extensions = members
+	}
+
+	return problem, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// GetProgress performs a http GET like Get but reads the body in chunks and reports the progress to onProgress as
+// bytes arrive. The total is taken from the Content-Length header and is -1 if unknown. Afterwards, the callback
+// receives the response with its body already read into memory.
+func GetProgress(url string, onProgress func(received, total int64), f func(res *http.Response, err error)) {
+	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	Request(DefaultClient(), req, func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		onProgress(0, res.ContentLength)
+
+		buf, err := ioutil.ReadAll(&progressReader{r: res.Body, total: res.ContentLength, onProgress: onProgress})
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		res.Body = ioutil.NopCloser(bytes.NewReader(buf))
+		f(res, nil)
+	})
+}
+
+// progressReader counts the bytes read from r and reports them to onProgress.
+type progressReader struct {
+	r          io.Reader
+	count      int64
+	total      int64
+	onProgress func(count, total int64)
+}
+
+// Read delegates to r and reports any progress.
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	if n > 0 {
+		p.count += int64(n)
+		p.onProgress(p.count, p.total)
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// PostProgress performs a http POST like Post and reports the amount of bytes of the body, which have been consumed
+// by the transport, to onProgress. The size is sent as Content-Length and passed as total. Note, that the wasm
+// transports have to read the entire body, before it is handed over to fetch, so the progress reflects the
+// preparation of the upload, not the actual network transfer.
+func PostProgress(url, contentType string, body io.Reader, size int64, onProgress func(sent, total int64),
+	f func(res *http.Response, err error)) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url,
+		&progressReader{r: body, total: size, onProgress: onProgress})
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	Request(DefaultClient(), req, f)
+}
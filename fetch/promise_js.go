@@ -0,0 +1,65 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import "syscall/js"
+
+// await blocks until the promise has been settled and returns its value or the rejection as js.Error. It must
+// not be called from within a js callback, because that would deadlock the event loop.
+func await(promise js.Value) (js.Value, error) {
+	values := make(chan js.Value, 1)
+	errs := make(chan error, 1)
+
+	var onResolve, onReject js.Func
+
+	onResolve = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		onResolve.Release()
+		onReject.Release()
+
+		values <- arg(args)
+
+		return nil
+	})
+
+	onReject = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		onResolve.Release()
+		onReject.Release()
+
+		errs <- js.Error{Value: arg(args)}
+
+		return nil
+	})
+
+	promise.Call("then", onResolve, onReject)
+
+	select {
+	case v := <-values:
+		return v, nil
+	case err := <-errs:
+		return js.Undefined(), err
+	}
+}
+
+// arg returns the first argument or undefined.
+func arg(args []js.Value) js.Value {
+	if len(args) == 0 {
+		return js.Undefined()
+	}
+
+	return args[0]
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// A Propagator injects the trace context of ctx into the headers of an outgoing request, e.g. as W3C traceparent
+// and tracestate headers. An adapter for the propagator of a tracing SDK only has to forward the call.
+type Propagator interface {
+	Inject(ctx context.Context, header http.Header)
+}
+
+// PropagatorFunc is an adapter to use an ordinary function as Propagator.
+type PropagatorFunc func(ctx context.Context, header http.Header)
+
+// Inject calls f(ctx, header).
+func (f PropagatorFunc) Inject(ctx context.Context, header http.Header) {
+	f(ctx, header)
+}
+
+// propagator contains the Propagator of SetPropagator.
+var propagator = struct { //nolint:gochecknoglobals
+	mutex      sync.Mutex
+	propagator Propagator
+}{propagator: W3CPropagator{}}
+
+// SetPropagator replaces the Propagator used by WithPropagation, which is a W3CPropagator by default. A nil
+// propagator disables the propagation.
+func SetPropagator(p Propagator) {
+	propagator.mutex.Lock()
+	defer propagator.mutex.Unlock()
+
+	propagator.propagator = p
+}
+
+// WithPropagation injects the trace context of ctx into the headers of the request, using the Propagator of
+// SetPropagator. Note, that the traceparent and tracestate headers are not CORS-safelisted, so a cross-origin server
+// has to allow them.
+func WithPropagation(ctx context.Context) Option {
+	return func(b *builder) error {
+		propagator.mutex.Lock()
+		p := propagator.propagator
+		propagator.mutex.Unlock()
+
+		if p != nil {
+			p.Inject(ctx, b.header)
+		}
+
+		return nil
+	}
+}
+
+// TraceContext contains the values of the W3C Trace Context headers.
+type TraceContext struct {
+	// TraceParent is the value of the traceparent header, e.g. 00-<trace-id>-<parent-id>-01.
+	TraceParent string
+	// TraceState is the optional value of the tracestate header.
+	TraceState string
+}
+
+// traceContextKey is the context key of a TraceContext.
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx, which carries the trace context for the W3CPropagator.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// W3CPropagator injects the TraceContext of ContextWithTraceContext as traceparent and tracestate headers.
+type W3CPropagator struct{}
+
+// Inject sets the headers, if ctx carries a TraceContext with a traceparent.
+func (W3CPropagator) Inject(ctx context.Context, header http.Header) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	if !ok || tc.TraceParent == "" {
+		return
+	}
+
+	header.Set("traceparent", tc.TraceParent)
+
+	if tc.TraceState != "" {
+		header.Set("tracestate", tc.TraceState)
+	}
+}
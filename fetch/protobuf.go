@@ -0,0 +1,55 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+)
+
+// An Unmarshaler decodes itself from the binary wire format, like the generated protobuf messages of the gogo
+// protobuf or vtprotobuf generators do. For the google.golang.org/protobuf messages, a small adapter which invokes
+// proto.Unmarshal fulfills this interface.
+type Unmarshaler interface {
+	Unmarshal(buf []byte) error
+}
+
+// AsProtobuf tries to unmarshal the body into the given message and invokes the callback afterwards. It works
+// exactly like AsJSON but delegates the decoding to the message itself, so that this package does not depend on a
+// specific protobuf library.
+func AsProtobuf(msg Unmarshaler, f func(err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		if err := msg.Unmarshal(buf); err != nil {
+			f(err)
+
+			return
+		}
+
+		f(nil)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetRange performs a http GET of the given byte range, both inclusive. A negative end requests an open range up
+// to the end of the resource. A server which supports ranges responds with 206 Partial Content, however a server may
+// also ignore the Range header and respond with 200 and the entire resource, so check the status code or use
+// ParseContentRange. An end smaller than start causes ErrInvalidRange.
+func GetRange(url string, start, end int64, f func(res *http.Response, err error)) {
+	if start < 0 || (end >= 0 && end < start) {
+		f(nil, fmt.Errorf("bytes=%d-%d: %w", start, end, ErrInvalidRange))
+
+		return
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	if end < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+
+	Request(DefaultClient(), req, f)
+}
+
+// ContentRange is a parsed Content-Range header like "bytes 0-499/1234". For an unsatisfied range like
+// "bytes */1234", Start and End are -1.
+type ContentRange struct {
+	// Start is the first byte position, inclusive.
+	Start int64
+	// End is the last byte position, inclusive.
+	End int64
+	// Size is the complete length of the resource or -1 if unknown.
+	Size int64
+}
+
+// ParseContentRange parses the Content-Range header of the response. If the response has no such header, ok is false.
+func ParseContentRange(res *http.Response) (cr ContentRange, ok bool, err error) {
+	value := res.Header.Get("Content-Range")
+	if value == "" {
+		return ContentRange{}, false, nil
+	}
+
+	invalid := fmt.Errorf("%s: %w", value, ErrInvalidRange)
+
+	spec := strings.TrimPrefix(value, "bytes ")
+	if spec == value {
+		return ContentRange{}, false, invalid
+	}
+
+	positions, size := spec, "*"
+	if i := strings.IndexByte(spec, '/'); i >= 0 {
+		positions, size = spec[:i], spec[i+1:]
+	}
+
+	cr.Size = -1
+	if size != "*" {
+		if cr.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return ContentRange{}, false, invalid
+		}
+	}
+
+	// an unsatisfied range of a 416 response
+	if positions == "*" {
+		return ContentRange{Start: -1, End: -1, Size: cr.Size}, true, nil
+	}
+
+	i := strings.IndexByte(positions, '-')
+	if i < 0 {
+		return ContentRange{}, false, invalid
+	}
+
+	if cr.Start, err = strconv.ParseInt(positions[:i], 10, 64); err != nil {
+		return ContentRange{}, false, invalid
+	}
+
+	if cr.End, err = strconv.ParseInt(positions[i+1:], 10, 64); err != nil || cr.End < cr.Start {
+		return ContentRange{}, false, invalid
+	}
+
+	return cr, true, nil
+}
+
+// AcceptsRanges returns true, if the Accept-Ranges header of the response announces support for byte ranges.
+func AcceptsRanges(res *http.Response) bool {
+	return strings.EqualFold(strings.TrimSpace(res.Header.Get("Accept-Ranges")), "bytes")
+}
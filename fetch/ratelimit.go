@@ -0,0 +1,157 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimitedClient returns a copy of the given client, which sends at most ratePerSec requests per second on
+// average, using a token bucket holding up to burst tokens. Requests are delayed in FIFO order, while the bucket is
+// empty. Cancelling a delayed request removes it from the queue immediately, returns its token and moves the requests
+// behind it forward. A ratePerSec of zero or less is rejected with ErrInvalidOption by each request and a burst of
+// less than 1 is treated as 1. A nil client is treated as the DefaultClient.
+func RateLimitedClient(client *http.Client, ratePerSec float64, burst int) *http.Client {
+	if !(ratePerSec > 0) {
+		err := fmt.Errorf("invalid rate '%v' per second: %w", ratePerSec, ErrInvalidOption)
+
+		return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+			return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return nil, err
+			})
+		})
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	bucket := &tokenBucket{rate: ratePerSec, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+
+	return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+		return RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if err := bucket.wait(req); err != nil {
+				return nil, err
+			}
+
+			return transport.RoundTrip(req)
+		})
+	})
+}
+
+// tokenBucket refills rate tokens per second up to burst. Tokens may become negative, which reserves the future
+// tokens for the waiting requests in the order of their arrival.
+type tokenBucket struct {
+	mutex   sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	waiting []*reservation
+}
+
+// reservation is a waiting request, which may send at the given time. Moved is notified, if the time has changed.
+type reservation struct {
+	at    time.Time
+	moved chan struct{}
+}
+
+// wait takes a token and blocks until it becomes available or the context of the request is done.
+func (b *tokenBucket) wait(req *http.Request) error {
+	b.mutex.Lock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	b.last = now
+
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	b.tokens--
+
+	if b.tokens >= 0 {
+		b.mutex.Unlock()
+
+		return nil
+	}
+
+	r := &reservation{at: now.Add(b.interval(-b.tokens)), moved: make(chan struct{}, 1)}
+	at := r.at
+	b.waiting = append(b.waiting, r)
+	b.mutex.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			b.remove(r, false)
+
+			return nil
+		case <-r.moved:
+			b.mutex.Lock()
+			at = r.at
+			b.mutex.Unlock()
+
+			timer.Reset(time.Until(at))
+		case <-req.Context().Done():
+			b.remove(r, true)
+
+			return req.Context().Err()
+		}
+	}
+}
+
+// interval returns the time to refill the given amount of tokens.
+func (b *tokenBucket) interval(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+// remove dequeues the reservation. A cancelled reservation returns its token, thus each later one may send a token
+// interval earlier.
+func (b *tokenBucket) remove(r *reservation, cancelled bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for i, w := range b.waiting {
+		if w != r {
+			continue
+		}
+
+		b.waiting = append(b.waiting[:i], b.waiting[i+1:]...)
+
+		if !cancelled {
+			return
+		}
+
+		b.tokens++
+
+		for _, later := range b.waiting[i:] {
+			later.at = later.at.Add(-b.interval(1))
+
+			select {
+			case later.moved <- struct{}{}:
+			default:
+			}
+		}
+
+		return
+	}
+}
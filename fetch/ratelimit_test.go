@@ -0,0 +1,123 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedClientInvalidRate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+	}{
+		{name: "zero", rate: 0},
+		{name: "negative", rate: -1},
+		{name: "not a number", rate: math.NaN()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := RateLimitedClient(NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return MockResponse(http.StatusOK, ""), nil
+			}), tt.rate, 1)
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := RequestSync(client, req); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("expected ErrInvalidOption, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRateLimitedClientCancel(t *testing.T) {
+	const interval = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		cancel  bool
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "waits for its reservation", cancel: false, wantMin: 2 * interval * 8 / 10, wantMax: 3 * interval},
+		{name: "moves up after a cancellation", cancel: true, wantMin: interval * 8 / 10, wantMax: interval * 17 / 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := RateLimitedClient(NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return MockResponse(http.StatusOK, ""), nil
+			}), float64(time.Second/interval), 1)
+
+			send := func(ctx context.Context) error {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/", nil)
+				if err != nil {
+					return err
+				}
+
+				_, err = RequestSync(client, req)
+
+				return err
+			}
+
+			start := time.Now()
+
+			// takes the only token of the burst
+			if err := send(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cancelled := make(chan error, 1)
+
+			go func() { cancelled <- send(ctx) }()
+
+			// let the second request reserve its token first
+			time.Sleep(interval / 5)
+
+			last := make(chan error, 1)
+
+			go func() { last <- send(context.Background()) }()
+
+			if tt.cancel {
+				time.Sleep(interval / 10)
+				cancel()
+			}
+
+			if err := <-last; err != nil {
+				t.Fatal(err)
+			}
+
+			if d := time.Since(start); d < tt.wantMin || d > tt.wantMax {
+				t.Fatalf("expected the last request after %v to %v, got %v", tt.wantMin, tt.wantMax, d)
+			}
+
+			if err := <-cancelled; tt.cancel != errors.Is(err, context.Canceled) {
+				t.Fatalf("unexpected error of the second request %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// AsHeaders is a middleware for an async http response, which is only interested in the headers and the status
+// code, e.g. for a Link or X-Total-Count header. The body is drained, before the callback is invoked.
+func AsHeaders(f func(h http.Header, status int, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, 0, err)
+
+			return
+		}
+
+		if err := Drain(res); err != nil {
+			f(nil, 0, err)
+
+			return
+		}
+
+		f(res.Header, res.StatusCode, nil)
+	}
+}
+
+// AsCookies is a middleware for an async http response, which forwards the cookies from the Set-Cookie headers of
+// the response. The body is drained, before the callback is invoked.
+//
+// Note, that within the browser, the Set-Cookie headers are forbidden response headers, so that the cookies are
+// usually invisible to wasm and the slice is empty. Only servers or custom transports which expose them, e.g.
+// by echoing a token, make this useful.
+func AsCookies(f func(cookies []*http.Cookie, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		if err := Drain(res); err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		f(res.Cookies(), nil)
+	}
+}
+
+// AsJSONStatus is a middleware for an async http response, which decodes a successful response (2xx) into success
+// and any other response into failure, e.g. an error envelope. The status code tells the callback, which target
+// has been populated. A nil target skips the decoding for its branch and an empty body of a failed response is
+// not decoded, because many servers and proxies do not send an envelope at all.
+func AsJSONStatus(success interface{}, failure interface{}, f func(status int, err error)) func(res *http.Response,
+	err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(0, err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(res.StatusCode, err)
+
+			return
+		}
+
+		target := success
+		if !isSuccess(res.StatusCode) {
+			target = failure
+
+			if len(buf) == 0 {
+				target = nil
+			}
+		}
+
+		if target != nil {
+			if err := json.Unmarshal(buf, target); err != nil {
+				f(res.StatusCode, err)
+
+				return
+			}
+		}
+
+		f(res.StatusCode, nil)
+	}
+}
+
+// Drain reads the remainder of the body and closes it, so that the connection can be reused. A nil response is
+// ignored and closing the body again, as Request does after the callback returns, is harmless.
+func Drain(res *http.Response) error {
+	if res == nil || res.Body == nil {
+		return nil
+	}
+
+	_, err := io.Copy(ioutil.Discard, res.Body)
+	if closeErr := res.Body.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// BodyInfo describes the body of a response, see Inspect.
+type BodyInfo struct {
+	// ContentType is the value of the Content-Type header, which may be empty.
+	ContentType string
+	// ContentLength is the actual length of the body, regardless of the Content-Length header.
+	ContentLength int64
+	// DetectedType is the type sniffed by http.DetectContentType from the first 512 bytes of the body.
+	DetectedType string
+}
+
+// Inspect is a middleware for an async http response, which reads the entire body and passes it together with its
+// BodyInfo to the callback, e.g. to select a decoder or to handle an empty body.
+func Inspect(f func(info BodyInfo, body []byte, err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(BodyInfo{}, nil, err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(BodyInfo{}, nil, err)
+
+			return
+		}
+
+		f(BodyInfo{
+			ContentType:   res.Header.Get("Content-Type"),
+			ContentLength: int64(len(buf)),
+			DetectedType:  http.DetectContentType(buf),
+		}, buf, nil)
+	}
+}
+
+// Tee is a middleware for an async http response, which reads the body once and passes the same bytes to each of
+// the decoders, e.g. to unmarshal it and to verify a checksum. All decoders are invoked, even if one fails, and the
+// callback receives their errors joined by errors.Join, or nil.
+func Tee(f func(err error), decoders ...func(body []byte) error) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		buf, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		errs := make([]error, 0, len(decoders))
+		for _, decode := range decoders {
+			errs = append(errs, decode(buf))
+		}
+
+		f(errors.Join(errs...))
+	}
+}
+
+// AsBase64Decoded is a middleware for an async http response, whose body contains base64 encoded binary data. The
+// standard alphabet is tried first, with the url safe alphabet as fallback, and padding is optional. Whitespace, like
+// line breaks of a wrapped payload, is ignored. If the body cannot be decoded, the error of the standard alphabet is
+// passed to the callback.
+func AsBase64Decoded(f func(data []byte, err error)) func(res *http.Response, err error) {
+	return AsBytes(func(buf []byte, err error) {
+		if err != nil {
+			f(nil, err)
+
+			return
+		}
+
+		f(decodeBase64(buf))
+	})
+}
+
+// decodeBase64 decodes the standard or url safe base64 encoded text, ignoring whitespace and any padding.
+func decodeBase64(text []byte) ([]byte, error) {
+	text = bytes.TrimRight(bytes.Join(bytes.Fields(text), nil), "=")
+	data := make([]byte, base64.RawStdEncoding.DecodedLen(len(text)))
+
+	n, err := base64.RawStdEncoding.Decode(data, text)
+	if err == nil {
+		return data[:n], nil
+	}
+
+	if n, urlErr := base64.RawURLEncoding.Decode(data, text); urlErr == nil {
+		return data[:n], nil
+	}
+
+	return nil, err //nolint:wrapcheck
+}
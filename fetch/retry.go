@@ -0,0 +1,241 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides which requests are retried by Retry and RetryWithPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum amount of attempts, including the first one.
+	MaxAttempts int
+	// Methods contains the http methods which are safe to be sent more than once. A request with an Idempotency-Key
+	// header is retried regardless of its method, see WithIdempotencyKey.
+	Methods map[string]bool
+	// StatusCodes contains the status codes indicating a temporary failure.
+	StatusCodes map[int]bool
+	// Backoff calculates the delay between the attempts. Nil retries immediately.
+	Backoff BackoffStrategy
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts and a jittered exponential backoff starting at 500ms, which only
+// retries the idempotent methods GET, HEAD, PUT, DELETE and OPTIONS on a *NetworkError or a temporary failure, like
+// 429 or 503. An ErrCircuitOpen of a BreakerClient is never retried.
+func DefaultRetryPolicy() RetryPolicy {
+	const (
+		defaultAttempts   = 3
+		defaultBackoff    = 500 * time.Millisecond
+		defaultMaxBackoff = 30 * time.Second
+	)
+
+	return RetryPolicy{
+		MaxAttempts: defaultAttempts,
+		Methods: map[string]bool{
+			http.MethodGet:     true,
+			http.MethodHead:    true,
+			http.MethodPut:     true,
+			http.MethodDelete:  true,
+			http.MethodOptions: true,
+		},
+		StatusCodes: map[int]bool{
+			http.StatusRequestTimeout:      true,
+			http.StatusTooManyRequests:     true,
+			http.StatusInternalServerError: true,
+			http.StatusBadGateway:          true,
+			http.StatusServiceUnavailable:  true,
+			http.StatusGatewayTimeout:      true,
+		},
+		Backoff: JitteredBackoff{Strategy: ExponentialBackoff{Initial: defaultBackoff, Max: defaultMaxBackoff}},
+	}
+}
+
+// RetryUnsafe returns the DefaultRetryPolicy which additionally retries POST and PATCH requests. Use it only, if the
+// server can cope with duplicate requests, otherwise records may be created twice.
+func RetryUnsafe() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.Methods[http.MethodPost] = true
+	policy.Methods[http.MethodPatch] = true
+
+	return policy
+}
+
+// Retry performs the request like Request but retries it up to the given amount of attempts, if it fails due to
+// a *NetworkError or a response with a status code indicating a temporary failure, like 429 or 503. Between the
+// attempts, it waits for the given backoff, which is doubled after each attempt. If the response contains a
+// Retry-After header, that delay is used instead. Only the final result is passed to the callback.
+//
+// Only the idempotent methods of the DefaultRetryPolicy are retried, use RetryWithPolicy and RetryUnsafe to
+// retry other methods as well. The body of the request is buffered, so that each attempt sends the same body.
+func Retry(client *http.Client, req *http.Request, attempts int, backoff time.Duration,
+	f func(res *http.Response, err error)) {
+	policy := DefaultRetryPolicy()
+	policy.MaxAttempts = attempts
+	policy.Backoff = ExponentialBackoff{Initial: backoff}
+
+	RetryWithPolicy(client, req, policy, f)
+}
+
+// RetryWithPolicy performs the request like Retry but decides by the given policy, which results are retried.
+func RetryWithPolicy(client *http.Client, req *http.Request, policy RetryPolicy,
+	f func(res *http.Response, err error)) {
+	req, finish := detach(req)
+
+	if err := rewindable(req); err != nil {
+		finish()
+		f(nil, err)
+
+		return
+	}
+
+	retry(client, req, 1, policy, func(res *http.Response, err error) {
+		defer finish()
+
+		f(res, err)
+	})
+}
+
+// retry performs the given attempt and schedules the next one if required.
+func retry(client *http.Client, req *http.Request, attempt int, policy RetryPolicy,
+	f func(res *http.Response, err error)) {
+	attemptReq, err := rewind(req)
+	if err != nil {
+		f(nil, err)
+
+		return
+	}
+
+	Request(client, attemptReq, func(res *http.Response, err error) {
+		if attempt >= policy.MaxAttempts || !policy.retryable(req, res, err) {
+			f(res, err)
+
+			return
+		}
+
+		var backoff time.Duration
+		if policy.Backoff != nil {
+			backoff = policy.Backoff.Next(attempt)
+		}
+
+		delay := retryAfter(res, backoff)
+		_ = Drain(res)
+
+		go func() {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+
+			// a cancelled context is delivered by the next attempt without waiting
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+			}
+
+			retry(client, req, attempt+1, policy, f)
+		}()
+	})
+}
+
+// retryable decides if the given result is a temporary failure and if the request may be sent again.
+func (p RetryPolicy) retryable(req *http.Request, res *http.Response, err error) bool {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if !p.Methods[method] && req.Header.Get(idempotencyKeyHeader) == "" {
+		return false
+	}
+
+	if err != nil {
+		var netErr *NetworkError
+
+		// an open circuit fails fast until its cool-down has elapsed, so retrying it would only hammer the breaker
+		return errors.As(err, &netErr) && !errors.Is(err, ErrCircuitOpen)
+	}
+
+	return p.StatusCodes[res.StatusCode]
+}
+
+// retryAfter parses the Retry-After header of the response, which is either in seconds or a http date. If the header
+// is not available, the fallback is returned.
+func retryAfter(res *http.Response, fallback time.Duration) time.Duration {
+	if res == nil {
+		return fallback
+	}
+
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return fallback
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+
+		return 0
+	}
+
+	return fallback
+}
+
+// rewindable ensures, that the request provides a GetBody function, by buffering the body if required.
+func rewindable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	buf, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buf)), nil
+	}
+
+	req.Body, _ = req.GetBody()
+
+	return nil
+}
+
+// rewind returns a copy of the rewindable request with a fresh body.
+func rewind(req *http.Request) (*http.Request, error) {
+	cpy := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return cpy, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	cpy.Body = body
+
+	return cpy, nil
+}
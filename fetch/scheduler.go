@@ -0,0 +1,68 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"sync"
+)
+
+// callbackScheduler contains the scheduler of SetCallbackScheduler.
+var callbackScheduler struct { //nolint:gochecknoglobals
+	mutex     sync.Mutex
+	scheduler func(fn func())
+}
+
+// SetCallbackScheduler determines when the callbacks of Request and the functions built upon it are invoked. The
+// scheduler must invoke fn exactly once, e.g. from within a requestAnimationFrame, queueMicrotask or setTimeout
+// callback, to batch the DOM updates of a response with the render loop. Because fn may block, e.g. while reading the
+// body, a js callback must invoke it within a new goroutine (go fn()), which the wasm runtime still executes before
+// it returns to the browser. The goroutine of the request waits until fn returns, so that the body stays valid. A
+// panic within fn is propagated to that goroutine and handled like any other panic of a callback.
+//
+// A nil scheduler restores the default, which invokes the callback directly from the goroutine of the request.
+func SetCallbackScheduler(scheduler func(fn func())) {
+	callbackScheduler.mutex.Lock()
+	defer callbackScheduler.mutex.Unlock()
+
+	callbackScheduler.scheduler = scheduler
+}
+
+// schedule invokes the callback using the current scheduler and waits until it has returned.
+func schedule(f callback, res *http.Response, err error) {
+	callbackScheduler.mutex.Lock()
+	scheduler := callbackScheduler.scheduler
+	callbackScheduler.mutex.Unlock()
+
+	if scheduler == nil {
+		f(res, err)
+
+		return
+	}
+
+	done := make(chan interface{}, 1)
+
+	scheduler(func() {
+		defer func() {
+			done <- recover()
+		}()
+
+		f(res, err)
+	})
+
+	if r := <-done; r != nil {
+		panic(r)
+	}
+}
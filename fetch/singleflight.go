@@ -0,0 +1,111 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// SingleFlightClient returns a copy of the given client, which coalesces concurrent GET and HEAD requests with the
+// same method and url into a single fetch. All callers receive their own copy of the response, whose body has been
+// read into memory. Note, that cancelling the request which has actually started the fetch, also fails the
+// coalesced requests. A nil client is treated as the DefaultClient.
+func SingleFlightClient(client *http.Client) *http.Client {
+	return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+		return &singleFlight{transport: transport, flights: map[string]*flight{}}
+	})
+}
+
+// WithCoalesceKey replaces the key, which decides whether a request performed by a SingleFlightClient is coalesced
+// with another one. The default key consists of the method and the url. A custom key allows to treat semantically
+// identical requests as the same one, e.g. by ignoring a cache buster in the query or by including a header, which
+// changes the response. A custom key should still contain the method, because only requests with the same key are
+// coalesced. An empty key performs the request on its own. It does not affect other clients.
+func WithCoalesceKey(key func(req *http.Request) string) Option {
+	return func(b *builder) error {
+		b.coalesceKey = key
+
+		return nil
+	}
+}
+
+// singleFlight is a http.RoundTripper which coalesces identical requests.
+type singleFlight struct {
+	transport http.RoundTripper
+	mutex     sync.Mutex
+	flights   map[string]*flight
+}
+
+// flight is a fetch in progress or its buffered result, after done has been closed.
+type flight struct {
+	done chan struct{}
+	res  *http.Response
+	body []byte
+	err  error
+}
+
+// RoundTrip either starts a new flight or waits for an already running one.
+func (s *singleFlight) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return s.transport.RoundTrip(req)
+	}
+
+	key := req.Method + " " + req.URL.String()
+	if cfg := configOf(req); cfg != nil && cfg.coalesceKey != nil {
+		if key = cfg.coalesceKey(req); key == "" {
+			return s.transport.RoundTrip(req)
+		}
+	}
+
+	s.mutex.Lock()
+	if f, ok := s.flights[key]; ok {
+		s.mutex.Unlock()
+
+		select {
+		case <-f.done:
+			return f.response(req)
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	s.flights[key] = f
+	s.mutex.Unlock()
+
+	f.res, f.err = s.transport.RoundTrip(req)
+	if f.err == nil {
+		f.body, f.err = ioutil.ReadAll(f.res.Body)
+		_ = f.res.Body.Close()
+	}
+
+	s.mutex.Lock()
+	delete(s.flights, key)
+	s.mutex.Unlock()
+	close(f.done)
+
+	return f.response(req)
+}
+
+// response returns a copy of the buffered result.
+func (f *flight) response(req *http.Request) (*http.Response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return cloneResponse(f.res, f.body, req), nil
+}
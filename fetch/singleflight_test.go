@@ -0,0 +1,106 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSingleFlightClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		urls      []string
+		opts      []Option
+		wantCalls int
+	}{
+		{name: "same url", method: http.MethodGet, urls: []string{"/a", "/a", "/a"}, wantCalls: 1},
+		{name: "different urls", method: http.MethodGet, urls: []string{"/a", "/b", "/c"}, wantCalls: 3},
+		{name: "head", method: http.MethodHead, urls: []string{"/a", "/a"}, wantCalls: 1},
+		{name: "post is never coalesced", method: http.MethodPost, urls: []string{"/a", "/a"}, wantCalls: 2},
+		{name: "custom key", method: http.MethodGet, urls: []string{"/a?t=1", "/a?t=2"},
+			opts:      []Option{WithCoalesceKey(func(req *http.Request) string { return req.Method + " " + req.URL.Path })},
+			wantCalls: 1},
+		{name: "empty key", method: http.MethodGet, urls: []string{"/a", "/a"},
+			opts: []Option{WithCoalesceKey(func(req *http.Request) string { return "" })}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex sync.Mutex
+				calls int
+			)
+
+			release := make(chan struct{})
+
+			client := SingleFlightClient(NewMockClient(func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				calls++
+				mutex.Unlock()
+
+				<-release
+
+				return MockResponse(http.StatusOK, req.URL.Path), nil
+			}))
+
+			var wg sync.WaitGroup
+
+			for _, path := range tt.urls {
+				opts := append([]Option{WithMethod(tt.method)}, tt.opts...)
+
+				req, err := NewRequest("https://my.domain"+path, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					result := <-RequestChan(client, req)
+					if result.Err != nil {
+						t.Error(result.Err)
+
+						return
+					}
+
+					buf, err := io.ReadAll(result.Res.Body)
+					if err != nil {
+						t.Error(err)
+					}
+
+					if tt.method != http.MethodHead && string(buf) != req.URL.Path {
+						t.Errorf("expected body '%s', got '%s'", req.URL.Path, buf)
+					}
+				}()
+			}
+
+			// let the coalesced requests join the running flight
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls != tt.wantCalls {
+				t.Fatalf("expected %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// AsJSONStream is a middleware for newline delimited JSON (NDJSON) responses. The body is decoded incrementally
+// and onItem is invoked for each value as soon as it has been received. Afterwards, the callback is invoked with
+// nil at the end of the stream or with the first decoding error.
+func AsJSONStream(onItem func(raw json.RawMessage), f func(err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		dec := json.NewDecoder(res.Body)
+
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				f(err)
+
+				return
+			}
+
+			onItem(raw)
+		}
+
+		f(nil) // success case
+	}
+}
+
+// AsJSONDecoder decodes the body into the given v like AsJSON, but uses a json.Decoder directly on the body stream,
+// instead of buffering the entire body first, which halves the peak memory for large responses. In contrast to
+// AsJSON, any data after the first JSON value is ignored. The callback is always invoked.
+func AsJSONDecoder(v interface{}, f func(err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			f(err)
+
+			return
+		}
+
+		f(nil) // success case
+	}
+}
+
+// AsJSONArray is a middleware for large JSON arrays, whose elements are decoded incrementally from the body, so that
+// the array is never buffered entirely. onItem is invoked for each element as soon as it has been decoded. If it
+// returns an error, decoding stops and the error is passed to the callback. Otherwise, the callback is invoked with
+// nil after the closing bracket or with the first decoding error, e.g. of a truncated array. A body, which is not an
+// array, causes ErrNotJSONArray. Any data after the array is ignored.
+func AsJSONArray[T any](onItem func(v T) error, f func(err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		f(decodeJSONArray(json.NewDecoder(res.Body), onItem))
+	}
+}
+
+// decodeJSONArray decodes all elements of the array and passes them to onItem.
+func decodeJSONArray[T any](dec *json.Decoder, onItem func(v T) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+
+			return err //nolint:wrapcheck
+		}
+
+		if err := onItem(v); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token, which must be the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			if delim == '[' {
+				return fmt.Errorf("empty body: %w", ErrNotJSONArray)
+			}
+
+			return io.ErrUnexpectedEOF
+		}
+
+		return err //nolint:wrapcheck
+	}
+
+	if token != delim {
+		return fmt.Errorf("unexpected token '%v': %w", token, ErrNotJSONArray)
+	}
+
+	return nil
+}
+
+// AsLines is a middleware for plain text streams, like a log tail. The body is scanned incrementally and onLine is
+// invoked for each line without its line ending, as soon as it has been received. Afterwards, the callback is invoked
+// with nil at the end of the body or with the first error. A line longer than 64KiB is reported as
+// bufio.ErrTooLong, see AsLinesBuffer.
+func AsLines(onLine func(line string), f func(err error)) func(res *http.Response, err error) {
+	return AsLinesBuffer(bufio.MaxScanTokenSize, onLine, f)
+}
+
+// AsLinesBuffer is like AsLines but accepts lines up to the given maximum size in bytes. A size of less than 1 is
+// rejected with ErrInvalidOption.
+func AsLinesBuffer(maxLineSize int, onLine func(line string), f func(err error)) func(res *http.Response, err error) {
+	return func(res *http.Response, err error) {
+		if maxLineSize < 1 {
+			f(fmt.Errorf("invalid max line size '%d': %w", maxLineSize, ErrInvalidOption))
+
+			return
+		}
+
+		if err != nil {
+			f(err)
+
+			return
+		}
+
+		const initialBufferSize = 4096
+
+		scanner := bufio.NewScanner(res.Body)
+		scanner.Buffer(make([]byte, 0, min(initialBufferSize, maxLineSize)), maxLineSize)
+
+		for scanner.Scan() {
+			onLine(scanner.Text())
+		}
+
+		f(scanner.Err())
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAsLinesBuffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		body    string
+		want    []string
+		wantErr error
+	}{
+		{name: "lines", max: 16, body: "a\nbc\r\n\nd", want: []string{"a", "bc", "", "d"}},
+		{name: "empty", max: 16, body: ""},
+		{name: "too long", max: 4, body: "abc\nabcdef\n", want: []string{"abc"}, wantErr: bufio.ErrTooLong},
+		{name: "zero", max: 0, body: "a\n", wantErr: ErrInvalidOption},
+		{name: "negative", max: -1, body: "a\n", wantErr: ErrInvalidOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				lines  []string
+				gotErr error
+			)
+
+			AsLinesBuffer(tt.max, func(line string) {
+				lines = append(lines, line)
+			}, func(err error) {
+				gotErr = err
+			})(MockResponse(http.StatusOK, tt.body), nil)
+
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, gotErr)
+			}
+
+			if tt.wantErr == nil && gotErr != nil {
+				t.Fatalf("expected no error, got %v", gotErr)
+			}
+
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Fatalf("expected %q, got %q", tt.want, lines)
+			}
+		})
+	}
+}
+
+func TestAsLinesRequestError(t *testing.T) {
+	want := errors.New("failed")
+
+	var got error
+
+	AsLines(func(line string) {
+		t.Fatalf("unexpected line '%s'", line)
+	}, func(err error) {
+		got = err
+	})(nil, want)
+
+	if !errors.Is(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ThrottledClient returns a copy of the given client, which allows at most max requests in flight at a time. Further
+// requests are queued in a roughly FIFO order. A slot is released, when the body of the response has been closed,
+// which Request does after the callback returns, or when the request fails. Cancelling a queued request removes it
+// from the queue immediately. A max of less than 1 is treated as 1. A nil client is treated as the DefaultClient.
+func ThrottledClient(client *http.Client, max int) *http.Client {
+	if max < 1 {
+		max = 1
+	}
+
+	return wrapTransport(client, func(transport http.RoundTripper) http.RoundTripper {
+		return &throttle{transport: transport, slots: make(chan struct{}, max)}
+	})
+}
+
+// throttle is a http.RoundTripper which limits the amount of concurrent requests.
+type throttle struct {
+	transport http.RoundTripper
+	slots     chan struct{}
+}
+
+// RoundTrip waits for a free slot and holds it until the body has been closed.
+func (t *throttle) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		<-t.slots
+
+		return nil, err
+	}
+
+	res.Body = &releasingBody{ReadCloser: res.Body, release: func() { <-t.slots }}
+
+	return res, nil
+}
+
+// releasingBody invokes release once, when closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close closes the body and invokes release.
+func (b *releasingBody) Close() error {
+	defer b.once.Do(b.release)
+
+	return b.ReadCloser.Close() //nolint:wrapcheck
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottledClient(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		want int
+	}{
+		{name: "negative", max: -1, want: 1},
+		{name: "zero", max: 0, want: 1},
+		{name: "one", max: 1, want: 1},
+		{name: "two", max: 2, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex         sync.Mutex
+				current, peak int
+				wg            sync.WaitGroup
+			)
+
+			client := ThrottledClient(NewMockClient(func(req *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				current++
+
+				if current > peak {
+					peak = current
+				}
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				current--
+				mutex.Unlock()
+
+				return MockResponse(http.StatusOK, ""), nil
+			}), tt.max)
+
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+
+				req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				Request(client, req, func(res *http.Response, err error) {
+					defer wg.Done()
+
+					if err != nil {
+						t.Error(err)
+					}
+				})
+			}
+
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("expected all requests to complete")
+			}
+
+			if peak > tt.want {
+				t.Fatalf("expected at most %d requests in flight, got %d", tt.want, peak)
+			}
+		})
+	}
+}
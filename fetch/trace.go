@@ -0,0 +1,123 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Trace contains the timing phases of a request, see WithTrace.
+type Trace struct {
+	// URL is the requested url.
+	URL string
+	// RequestStart is the time, when the request has been started.
+	RequestStart time.Time
+	// ResponseStart is the time, when the response headers have been received, before the callback is scheduled, see
+	// SetCallbackScheduler. Within the browser, the first byte of the PerformanceResourceTiming entry is used, if
+	// available. It is zero, if the request failed.
+	ResponseStart time.Time
+	// ResponseEnd is the time, when the body has been read completely or closed, or when the request failed.
+	ResponseEnd time.Time
+	// Err is the error of the request, if any.
+	Err error
+}
+
+// TimeToFirstByte returns the duration between RequestStart and ResponseStart.
+func (t Trace) TimeToFirstByte() time.Duration {
+	if t.ResponseStart.IsZero() {
+		return 0
+	}
+
+	return t.ResponseStart.Sub(t.RequestStart)
+}
+
+// Duration returns the duration between RequestStart and ResponseEnd.
+func (t Trace) Duration() time.Duration {
+	return t.ResponseEnd.Sub(t.RequestStart)
+}
+
+// WithTrace invokes f with the timing phases of the request, as soon as its body has been read or closed, which
+// happens at the latest after the callback has returned. The phases include the interceptors, the hooks and the
+// waiting time of throttling clients. Each attempt of a Retry is traced individually.
+func WithTrace(f func(t Trace)) Option {
+	return func(b *builder) error {
+		b.wrap = append(b.wrap, func(client *http.Client, req *http.Request, next callback) callback {
+			trace := Trace{URL: req.URL.String(), RequestStart: time.Now()}
+
+			if cfg := configOf(req); cfg != nil {
+				cfg.onResponse = append(cfg.onResponse, func() {
+					trace.ResponseStart = time.Now()
+				})
+			}
+
+			return func(res *http.Response, err error) {
+				if err != nil {
+					trace.ResponseStart = time.Time{}
+					trace.ResponseEnd = time.Now()
+					trace.Err = err
+					f(trace)
+					next(res, err)
+
+					return
+				}
+
+				if trace.ResponseStart.IsZero() {
+					trace.ResponseStart = time.Now()
+				}
+
+				body := &tracingBody{ReadCloser: res.Body, end: func() {
+					trace.ResponseEnd = time.Now()
+					applyResourceTiming(&trace)
+					f(trace)
+				}}
+
+				// Request closes the original body, thus the trace is ended here
+				defer body.Close() //nolint:errcheck
+
+				res.Body = body
+				next(res, err)
+			}
+		})
+
+		return nil
+	}
+}
+
+// tracingBody invokes end once, when the body has been read completely or closed.
+type tracingBody struct {
+	io.ReadCloser
+	once sync.Once
+	end  func()
+}
+
+// Read reads from the body and ends the trace at EOF.
+func (b *tracingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.end)
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// Close closes the body and ends the trace.
+func (b *tracingBody) Close() error {
+	defer b.once.Do(b.end)
+
+	return b.ReadCloser.Close() //nolint:wrapcheck
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"syscall/js"
+	"time"
+)
+
+// applyResourceTiming replaces the ResponseStart of the trace by the responseStart of the PerformanceResourceTiming
+// entry of its url, which marks the arrival of the first byte within the browser. The latest entry is used, unless it
+// has been started before the trace. A cross-origin entry only provides the value, if the server allows it by the
+// Timing-Allow-Origin header.
+func applyResourceTiming(trace *Trace) {
+	performance := js.Global().Get("performance")
+	if performance.Type() != js.TypeObject || performance.Get("getEntriesByName").Type() != js.TypeFunction {
+		return
+	}
+
+	origin := performance.Get("timeOrigin").Float()
+	entries := performance.Call("getEntriesByName", trace.URL, "resource")
+
+	if entries.Length() == 0 {
+		return
+	}
+
+	entry := entries.Index(entries.Length() - 1)
+	if highResTime(origin, entry.Get("startTime").Float()).Before(trace.RequestStart) {
+		return
+	}
+
+	if responseStart := entry.Get("responseStart").Float(); responseStart > 0 {
+		trace.ResponseStart = highResTime(origin, responseStart)
+	}
+}
+
+// highResTime converts the DOMHighResTimeStamp in milliseconds relative to the time origin.
+func highResTime(origin, timestamp float64) time.Time {
+	const microsPerMilli = 1000
+
+	return time.UnixMicro(int64((origin + timestamp) * microsPerMilli))
+}
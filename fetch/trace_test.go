@@ -0,0 +1,89 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTraceTimeToFirstByte(t *testing.T) {
+	const delay = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "response"},
+		{name: "error", err: io.ErrUnexpectedEOF, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetCallbackScheduler(func(fn func()) {
+				time.Sleep(delay)
+				fn()
+			})
+			t.Cleanup(func() { SetCallbackScheduler(nil) })
+
+			client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+
+				return MockResponse(http.StatusOK, "hello"), nil
+			})
+
+			traces := make(chan Trace, 1)
+
+			req, err := NewRequest("http://localhost/", WithTrace(func(trace Trace) { traces <- trace }))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			Request(client, req, func(res *http.Response, err error) {})
+
+			var trace Trace
+			select {
+			case trace = <-traces:
+			case <-time.After(time.Second):
+				t.Fatal("trace not reported")
+			}
+
+			if (trace.Err != nil) != tt.wantErr {
+				t.Fatalf("unexpected trace error %v", trace.Err)
+			}
+
+			if tt.wantErr {
+				if !trace.ResponseStart.IsZero() {
+					t.Fatalf("expected no response start, got %v", trace.ResponseStart)
+				}
+
+				return
+			}
+
+			if ttfb := trace.TimeToFirstByte(); ttfb <= 0 || ttfb >= delay {
+				t.Fatalf("expected a time to first byte below %v, got %v", delay, ttfb)
+			}
+
+			if d := trace.Duration(); d < delay {
+				t.Fatalf("expected a duration of at least %v, got %v", delay, d)
+			}
+		})
+	}
+}
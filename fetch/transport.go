@@ -0,0 +1,124 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// wrapTransport returns a shallow copy of the given client, whose transport has been wrapped. A nil client is
+// treated as the DefaultClient.
+func wrapTransport(client *http.Client, wrap func(transport http.RoundTripper) http.RoundTripper) *http.Client {
+	if client == nil {
+		client = DefaultClient()
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	cpy := *client
+	cpy.Transport = &wrappedTransport{RoundTripper: wrap(transport), base: transport}
+
+	return &cpy
+}
+
+// wrappedTransport is the transport of a client returned by wrapTransport, which remembers the wrapped transport.
+type wrappedTransport struct {
+	http.RoundTripper
+	base http.RoundTripper
+}
+
+// supportsFetchOptions returns true, if the wrapped transport understands the fetch options.
+func (t *wrappedTransport) supportsFetchOptions() bool {
+	return understandsFetchOptions(t.base)
+}
+
+// fetchOptionsTransport is implemented by transports, which understand the fetch options, which are unknown to the
+// transport of net/http, like WithCache.
+type fetchOptionsTransport interface {
+	supportsFetchOptions() bool
+}
+
+// understandsFetchOptions returns true, if the transport understands the fetch options, see fetchOptionsTransport.
+func understandsFetchOptions(transport http.RoundTripper) bool {
+	t, ok := transport.(fetchOptionsTransport)
+
+	return ok && t.supportsFetchOptions()
+}
+
+// cloneResponse returns a copy of the response with a replayable body and the given request.
+func cloneResponse(res *http.Response, body []byte, req *http.Request) *http.Response {
+	cpy := *res
+	cpy.Header = res.Header.Clone()
+	cpy.Body = ioutil.NopCloser(bytes.NewReader(body))
+	cpy.Request = req
+
+	return &cpy
+}
+
+// RoundTripFunc is an adapter to use an ordinary function as http.RoundTripper.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// NewMockClient returns a http client, which does not touch the network but passes each request to the given
+// function. This allows to test code using Request and the middlewares deterministically. Example:
+//
+//	client := NewMockClient(func(req *http.Request) (*http.Response, error) {
+//	    return MockResponse(http.StatusOK, `{"name":"gopher"}`), nil
+//	})
+//
+//	Request(client, req, AsJSON(&v, func(err error) {
+//	    // assert v
+//	}))
+func NewMockClient(f func(req *http.Request) (*http.Response, error)) *http.Client {
+	return &http.Client{Transport: RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := f(req)
+		if res != nil {
+			if res.Request == nil {
+				res.Request = req
+			}
+
+			if res.Body == nil {
+				res.Body = http.NoBody
+			}
+		}
+
+		return res, err
+	})}
+}
+
+// MockResponse creates a minimal response with the given status code and body, e.g. for a NewMockClient.
+func MockResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall/js"
+)
+
+// NewFetchClient returns a new http client, which uses the FetchTransport.
+func NewFetchClient() *http.Client {
+	return &http.Client{Transport: &FetchTransport{}}
+}
+
+// FetchTransport is a http.RoundTripper which invokes the browser fetch API directly, instead of using the wasm
+// transport of net/http. Besides the mode, credentials and redirect options, it also supports the cache option, see
+// WithCache. Cancelling the context of a request aborts the fetch using an AbortController. The body of the response
+// is read using the arrayBuffer promise, when it is read for the first time.
+//
+// RoundTrip blocks until the fetch promise has been settled, thus it must not be called from the UI or DOM Thread,
+// which is guaranteed when used with Request.
+type FetchTransport struct{}
+
+// supportsFetchOptions returns true, because the FetchTransport understands all fetch options.
+func (t *FetchTransport) supportsFetchOptions() bool {
+	return true
+}
+
+// RoundTrip performs the request using fetch.
+func (t *FetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, done, err := jsFetch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newResponse(req, response, done)
+}
+
+// jsFetch performs the request using fetch and returns the settled js response. The returned channel must be closed,
+// when the response is not required anymore, which the body of newResponse does.
+func jsFetch(req *http.Request) (js.Value, chan struct{}, error) {
+	init, err := newFetchInit(req)
+	if err != nil {
+		return js.Undefined(), nil, err
+	}
+
+	done := make(chan struct{})
+
+	// not all browsers which support wasm, also support the AbortController
+	if controller := js.Global().Get("AbortController"); !controller.IsUndefined() {
+		controller = controller.New()
+		init.Set("signal", controller.Get("signal"))
+		abortOnCancel(req.Context(), controller, done)
+	}
+
+	response, err := await(js.Global().Call("fetch", req.URL.String(), init))
+	if err != nil {
+		close(done)
+
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return js.Undefined(), nil, ctxErr
+		}
+
+		return js.Undefined(), nil, err
+	}
+
+	return response, done, nil
+}
+
+// newFetchInit creates the init object for fetch from the request, which also consumes and closes the body.
+func newFetchInit(req *http.Request) (js.Value, error) {
+	init := js.Global().Get("Object").New()
+	init.Set("method", req.Method)
+
+	headers := js.Global().Get("Headers").New()
+
+	for key, values := range req.Header {
+		if key == jsFetchResponseType {
+			continue
+		}
+
+		if key == jsFetchKeepalive {
+			init.Set("keepalive", values[0] == "true")
+
+			continue
+		}
+
+		if strings.HasPrefix(key, "js.fetch:") {
+			init.Set(strings.TrimPrefix(key, "js.fetch:"), values[0])
+
+			continue
+		}
+
+		for _, value := range values {
+			headers.Call("append", key, value)
+		}
+	}
+
+	init.Set("headers", headers)
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+
+		if err != nil {
+			return js.Undefined(), err
+		}
+
+		if len(body) > maxKeepaliveBody && req.Header.Get(jsFetchKeepalive) == "true" {
+			return js.Undefined(), fmt.Errorf("%w: keepalive requests are limited to %d bytes", ErrBodyTooLarge,
+				maxKeepaliveBody)
+		}
+
+		if len(body) > 0 {
+			buf := js.Global().Get("Uint8Array").New(len(body))
+			js.CopyBytesToJS(buf, body)
+			init.Set("body", buf)
+		}
+	}
+
+	return init, nil
+}
+
+// abortOnCancel aborts the controller, if the context is cancelled before done is closed.
+func abortOnCancel(ctx context.Context, controller js.Value, done <-chan struct{}) {
+	if ctx.Done() == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			controller.Call("abort")
+		case <-done:
+		}
+	}()
+}
+
+// newResponse converts the fetch response. The request of the response contains the final url, if redirected.
+func newResponse(req *http.Request, response js.Value, done chan struct{}) (*http.Response, error) {
+	header := http.Header{}
+	entries := response.Get("headers").Call("entries")
+
+	for {
+		next := entries.Call("next")
+		if next.Get("done").Bool() {
+			break
+		}
+
+		pair := next.Get("value")
+		header.Add(pair.Index(0).String(), pair.Index(1).String())
+	}
+
+	contentLength := int64(-1)
+	if value := header.Get("Content-Length"); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n >= 0 {
+			contentLength = n
+		}
+	}
+
+	// fetch has already decoded the body
+	if header.Get("Content-Encoding") != "" {
+		header.Del("Content-Encoding")
+		header.Del("Content-Length")
+
+		contentLength = -1
+	}
+
+	if response.Get("redirected").Bool() {
+		req = req.Clone(req.Context())
+		if u, err := req.URL.Parse(response.Get("url").String()); err == nil {
+			req.URL = u
+			req.Host = u.Host
+		}
+	}
+
+	code := response.Get("status").Int()
+
+	statusText := response.Get("statusText").String()
+	if statusText == "" {
+		statusText = http.StatusText(code)
+	}
+
+	body := &arrayBufferBody{
+		ctx:          req.Context(),
+		response:     response,
+		responseType: req.Header.Get(jsFetchResponseType),
+		done:         done,
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, statusText),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          body,
+		ContentLength: contentLength,
+		Request:       req,
+	}, nil
+}
+
+// arrayBufferBody reads the body of a fetch response using its arrayBuffer promise, or the one of the response
+// type, see WithResponseType.
+type arrayBufferBody struct {
+	ctx          context.Context //nolint:containedctx
+	response     js.Value
+	responseType string
+	buf          *bytes.Reader
+	err          error
+	done         chan struct{}
+	closed       bool
+}
+
+// Read awaits the arrayBuffer promise on the first invocation.
+func (b *arrayBufferBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	if b.buf == nil {
+		data, err := b.readAll()
+		if err != nil {
+			b.err = err
+			if ctxErr := b.ctx.Err(); ctxErr != nil {
+				b.err = ctxErr
+			}
+
+			return 0, b.err
+		}
+
+		b.buf = bytes.NewReader(data)
+	}
+
+	n, err := b.buf.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+
+	return n, err //nolint:wrapcheck
+}
+
+// readAll awaits the promise of the response type and converts its value.
+func (b *arrayBufferBody) readAll() ([]byte, error) {
+	switch b.responseType {
+	case "text":
+		value, err := await(b.response.Call("text"))
+		if err != nil {
+			return nil, err
+		}
+
+		return []byte(value.String()), nil
+	case "blob":
+		value, err := await(b.response.Call("blob"))
+		if err != nil {
+			return nil, err
+		}
+
+		return blobBytes(value)
+	default:
+		value, err := await(b.response.Call("arrayBuffer"))
+		if err != nil {
+			return nil, err
+		}
+
+		return arrayBufferBytes(value), nil
+	}
+}
+
+// blob awaits the blob promise, if the body has not been read yet.
+func (b *arrayBufferBody) blob() (js.Value, bool, error) {
+	if b.buf != nil || b.err != nil || b.closed {
+		return js.Undefined(), false, nil
+	}
+
+	defer b.release()
+
+	value, err := await(b.response.Call("blob"))
+	if err != nil {
+		if ctxErr := b.ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+
+		b.err = err
+
+		return js.Undefined(), true, err
+	}
+
+	b.buf = bytes.NewReader(nil)
+
+	return value, true, nil
+}
+
+// arrayBufferBytes copies the ArrayBuffer into the Go heap.
+func arrayBufferBytes(value js.Value) []byte {
+	arr := js.Global().Get("Uint8Array").New(value)
+	data := make([]byte, arr.Get("length").Int())
+	js.CopyBytesToGo(data, arr)
+
+	return data
+}
+
+// blobBytes copies the Blob into the Go heap.
+func blobBytes(blob js.Value) ([]byte, error) {
+	value, err := await(blob.Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+
+	return arrayBufferBytes(value), nil
+}
+
+// Close stops watching the context.
+func (b *arrayBufferBody) Close() error {
+	b.release()
+
+	return nil
+}
+
+// release ends the goroutine of abortOnCancel.
+func (b *arrayBufferBody) release() {
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetch
+
+import (
+	"net/http"
+	"runtime"
+)
+
+// defaultUserAgent is applied to each request by Request, see SetDefaultUserAgent.
+var defaultUserAgent string //nolint:gochecknoglobals
+
+// SetDefaultUserAgent sets the agent, which is applied to each request, that does not already define one. An empty
+// agent disables the default, which is also the initial state. It should be called once at startup.
+//
+// Browsers treat User-Agent as a forbidden header name for fetch and silently drop it, so within the browser the
+// agent is sent as X-App-Agent instead. Note, that this custom header requires a CORS preflight for cross-origin
+// requests.
+func SetDefaultUserAgent(ua string) {
+	defaultUserAgent = ua
+}
+
+// WithUserAgent sets the agent of the request, overriding the default. See also SetDefaultUserAgent.
+func WithUserAgent(ua string) Option {
+	return func(b *builder) error {
+		b.header.Set(userAgentHeader(), ua)
+
+		return nil
+	}
+}
+
+// userAgentHeader returns the header name, which is used for the agent on the current platform.
+func userAgentHeader() string {
+	if runtime.GOOS == "js" {
+		return "X-App-Agent"
+	}
+
+	return "User-Agent"
+}
+
+// applyUserAgent sets the default agent, if the request does not define one.
+func applyUserAgent(req *http.Request) {
+	if defaultUserAgent == "" {
+		return
+	}
+
+	key := userAgentHeader()
+	if req.Header.Get(key) == "" {
+		req.Header.Set(key, defaultUserAgent)
+	}
+}
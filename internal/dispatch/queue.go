@@ -0,0 +1,96 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dispatch provides a queue to invoke callbacks from js event handlers sequentially without blocking the
+// js event loop.
+package dispatch
+
+import "sync"
+
+// Queue executes the posted jobs one after another in the order of posting, using a single goroutine. A job must
+// not panic, thus it must recover itself if required.
+type Queue struct {
+	mutex  sync.Mutex
+	jobs   []func()
+	wakeup chan struct{}
+	closed bool
+}
+
+// New creates a queue and starts its goroutine.
+func New() *Queue {
+	q := &Queue{wakeup: make(chan struct{}, 1)}
+	go q.loop()
+
+	return q
+}
+
+// Post appends the job and never blocks. Jobs posted after Close are discarded.
+func (q *Queue) Post(job func()) {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+
+		return
+	}
+
+	q.jobs = append(q.jobs, job)
+	q.mutex.Unlock()
+
+	q.notify()
+}
+
+// Close discards all pending jobs and stops the goroutine. A currently running job is not interrupted.
+func (q *Queue) Close() {
+	q.mutex.Lock()
+	q.closed = true
+	q.jobs = nil
+	q.mutex.Unlock()
+
+	q.notify()
+}
+
+// notify wakes up the loop, if it is not already awake.
+func (q *Queue) notify() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// loop executes the jobs until the queue has been closed.
+func (q *Queue) loop() {
+	for range q.wakeup {
+		for {
+			q.mutex.Lock()
+			if q.closed {
+				q.mutex.Unlock()
+
+				return
+			}
+
+			if len(q.jobs) == 0 {
+				q.mutex.Unlock()
+
+				break
+			}
+
+			job := q.jobs[0]
+			q.jobs[0] = nil
+			q.jobs = q.jobs[1:]
+			q.mutex.Unlock()
+
+			job()
+		}
+	}
+}
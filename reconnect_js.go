@@ -0,0 +1,313 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package net
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golangee/wasm-net/fetch"
+)
+
+// ReconnectConfig configures a ReconnectingWebSocket.
+type ReconnectConfig struct {
+	// URL to connect to.
+	URL string
+	// Protocols are the optional sub protocols.
+	Protocols []string
+	// Backoff calculates the delay before the next connection attempt, after the connection has been lost. The
+	// attempts are counted from 1 and reset by each successful connection. Nil uses a jittered exponential backoff
+	// starting at 500ms up to 30s.
+	Backoff fetch.BackoffStrategy
+	// DropWhileDisconnected discards outgoing messages, while the connection is lost, and the send methods return
+	// ErrWebSocketClosed. Otherwise, the messages are buffered and sent after reconnecting.
+	DropWhileDisconnected bool
+}
+
+// normalClosure is the close code of a WebSocket, whose purpose has been fulfilled.
+const normalClosure = 1000
+
+// ReconnectingWebSocket wraps a WebSocket, which reconnects after each unexpected close, until Close is called or
+// the server closes the connection normally, i.e. cleanly with the code 1000. Its callbacks survive reconnections and
+// are invoked like the ones of a WebSocket.
+type ReconnectingWebSocket struct {
+	cfg         ReconnectConfig
+	mutex       sync.Mutex
+	ws          *WebSocket
+	connected   bool
+	reconnect   bool
+	attempt     int
+	closed      bool
+	pending     []frame
+	onMessage   func(data []byte, isText bool)
+	onJSON      func(raw json.RawMessage)
+	onReconnect func()
+	onClose     func(code int, reason string)
+}
+
+// frame is an outgoing message buffered by a ReconnectingWebSocket.
+type frame struct {
+	data   []byte
+	isText bool
+}
+
+// DialReconnectingWebSocket connects to the configured url like DialWebSocket and returns immediately. An error is
+// returned, if the browser rejects the url or the protocols.
+func DialReconnectingWebSocket(cfg ReconnectConfig) (*ReconnectingWebSocket, error) {
+	if cfg.Backoff == nil {
+		const (
+			defaultBackoff    = 500 * time.Millisecond
+			defaultMaxBackoff = 30 * time.Second
+		)
+
+		cfg.Backoff = fetch.JitteredBackoff{
+			Strategy: fetch.ExponentialBackoff{Initial: defaultBackoff, Max: defaultMaxBackoff},
+		}
+	}
+
+	r := &ReconnectingWebSocket{cfg: cfg}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.dial(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// OnMessage sets the callback for received messages, see WebSocket.OnMessage.
+func (r *ReconnectingWebSocket) OnMessage(f func(data []byte, isText bool)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.onMessage = f
+}
+
+// OnJSON sets the callback for received text frames, see WebSocket.OnJSON.
+func (r *ReconnectingWebSocket) OnJSON(f func(raw json.RawMessage)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.onJSON = f
+}
+
+// OnReconnect sets the callback, which is invoked each time the connection has been established again after it was
+// lost, e.g. to resubscribe to server side topics. Buffered messages have already been sent at this point.
+func (r *ReconnectingWebSocket) OnReconnect(f func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.onReconnect = f
+}
+
+// OnClose sets the callback, which is invoked once the connection has been closed permanently, either by Close or
+// normally by the server. Afterwards, the send methods return ErrWebSocketClosed.
+func (r *ReconnectingWebSocket) OnClose(f func(code int, reason string)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.onClose = f
+}
+
+// Send sends the data as a binary frame.
+func (r *ReconnectingWebSocket) Send(data []byte) error {
+	return r.send(frame{data: data})
+}
+
+// SendText sends the text as a text frame.
+func (r *ReconnectingWebSocket) SendText(text string) error {
+	return r.send(frame{data: []byte(text), isText: true})
+}
+
+// SendJSON marshals the value and sends it as a text frame, see WebSocket.SendJSON.
+func (r *ReconnectingWebSocket) SendJSON(v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return r.send(frame{data: buf, isText: true})
+}
+
+// Close closes the connection normally and stops reconnecting permanently. Buffered messages are discarded.
+func (r *ReconnectingWebSocket) Close() {
+	r.mutex.Lock()
+	ws := r.ws
+	r.closed = true
+	r.pending = nil
+	r.mutex.Unlock()
+
+	if ws != nil {
+		ws.Close()
+	}
+}
+
+// send transmits the frame or buffers it while disconnected.
+func (r *ReconnectingWebSocket) send(f frame) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return ErrWebSocketClosed
+	}
+
+	// the connection may have been lost before the close event has been dispatched
+	if r.connected {
+		if err := f.sendTo(r.ws); !errors.Is(err, ErrWebSocketClosed) {
+			return err
+		}
+	}
+
+	if r.cfg.DropWhileDisconnected {
+		return ErrWebSocketClosed
+	}
+
+	r.pending = append(r.pending, f)
+
+	return nil
+}
+
+// sendTo transmits the frame using the given WebSocket.
+func (f frame) sendTo(ws *WebSocket) error {
+	if f.isText {
+		return ws.SendText(string(f.data))
+	}
+
+	return ws.Send(f.data)
+}
+
+// dial creates a new WebSocket, whose callbacks delegate to the current ones. The mutex must be held.
+func (r *ReconnectingWebSocket) dial() error {
+	ws, err := DialWebSocket(r.cfg.URL, r.cfg.Protocols...)
+	if err != nil {
+		return err
+	}
+
+	ws.mutex.Lock()
+	ws.onOpen = func() { r.handleOpen(ws) }
+	ws.onClose = func(code int, reason string) { r.handleClose(ws, code, reason) }
+	ws.onMessage = r.handleMessage
+	ws.mutex.Unlock()
+
+	r.ws = ws
+
+	return nil
+}
+
+// handleOpen flushes the buffered messages and notifies about a reconnection.
+func (r *ReconnectingWebSocket) handleOpen(ws *WebSocket) {
+	r.mutex.Lock()
+	if r.closed || r.ws != ws {
+		r.mutex.Unlock()
+
+		return
+	}
+
+	r.connected = true
+	r.attempt = 0
+
+	sent := 0
+
+	for _, f := range r.pending {
+		if err := f.sendTo(ws); err != nil {
+			break
+		}
+
+		sent++
+	}
+
+	// the frames, which could not be sent, are kept for the next connection
+	r.pending = append([]frame(nil), r.pending[sent:]...)
+	f := r.onReconnect
+	reconnected := r.reconnect
+	r.reconnect = true
+	r.mutex.Unlock()
+
+	if reconnected && f != nil {
+		f()
+	}
+}
+
+// handleMessage delegates to the current callbacks.
+func (r *ReconnectingWebSocket) handleMessage(data []byte, isText bool) {
+	r.mutex.Lock()
+	f, onJSON := r.onMessage, r.onJSON
+	r.mutex.Unlock()
+
+	if f != nil {
+		f(data, isText)
+	}
+
+	if onJSON != nil && isText {
+		onJSON(data)
+	}
+}
+
+// handleClose schedules the next connection attempt, unless the connection has been closed on purpose, either by
+// Close or normally by the server.
+func (r *ReconnectingWebSocket) handleClose(ws *WebSocket, code int, reason string) {
+	ws.mutex.Lock()
+	wasClean := ws.wasClean
+	ws.mutex.Unlock()
+
+	r.mutex.Lock()
+	if r.ws != ws {
+		r.mutex.Unlock()
+
+		return
+	}
+
+	r.connected = false
+
+	if !r.closed && !(wasClean && code == normalClosure) {
+		r.attempt++
+		time.AfterFunc(r.cfg.Backoff.Next(r.attempt), r.redial)
+		r.mutex.Unlock()
+
+		return
+	}
+
+	r.closed = true
+	r.pending = nil
+	f := r.onClose
+	r.mutex.Unlock()
+
+	if f != nil {
+		f(code, reason)
+	}
+}
+
+// redial connects again, unless Close has been called in the meantime.
+func (r *ReconnectingWebSocket) redial() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	// a rejected url is not expected to recover, but it is retried like a lost connection
+	if err := r.dial(); err != nil {
+		r.attempt++
+		time.AfterFunc(r.cfg.Backoff.Next(r.attempt), r.redial)
+	}
+}
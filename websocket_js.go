@@ -0,0 +1,266 @@
+// Copyright 2020 Torben Schinke
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build js
+// +build js
+
+package net
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"syscall/js"
+
+	"github.com/golangee/wasm-net/fetch"
+	"github.com/golangee/wasm-net/internal/dispatch"
+)
+
+// the readyState values of a browser WebSocket.
+const (
+	wsConnecting = 0
+	wsOpen       = 1
+)
+
+// ErrWebSocketClosed is returned when sending on a closing or closed WebSocket.
+var ErrWebSocketClosed = errors.New("net: websocket closed")
+
+// WebSocket wraps a browser WebSocket. All callbacks are invoked in order and from a goroutine, like the callbacks
+// of the fetch package, so they do not race with the UI or DOM Thread.
+type WebSocket struct {
+	value     js.Value
+	queue     *dispatch.Queue
+	mutex     sync.Mutex
+	onMessage func(data []byte, isText bool)
+	onJSON    func(raw json.RawMessage)
+	onClose   func(code int, reason string)
+	onOpen    func()
+	wasClean  bool
+	pending   []js.Value
+	funcs     []js.Func
+}
+
+// DialWebSocket connects to the given url and returns immediately, without waiting for the connection to be
+// established. Messages which are sent while connecting are buffered and sent as soon as the connection is open.
+// An error is returned, if the browser rejects the url or the protocols.
+func DialWebSocket(url string, protocols ...string) (ws *WebSocket, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			jsErr, ok := r.(js.Error)
+			if !ok {
+				panic(r)
+			}
+
+			ws, err = nil, jsErr
+		}
+	}()
+
+	args := []interface{}{url}
+	if len(protocols) > 0 {
+		list := make([]interface{}, 0, len(protocols))
+		for _, protocol := range protocols {
+			list = append(list, protocol)
+		}
+
+		args = append(args, list)
+	}
+
+	ws = &WebSocket{value: js.Global().Get("WebSocket").New(args...), queue: dispatch.New()}
+	ws.value.Set("binaryType", "arraybuffer")
+	ws.listen("open", ws.handleOpen)
+	ws.listen("message", ws.handleMessage)
+	ws.listen("close", ws.handleClose)
+
+	return ws, nil
+}
+
+// OnMessage sets the callback for received messages. Text frames are passed as their UTF-8 bytes and isText is
+// true. Binary frames are passed as is.
+func (ws *WebSocket) OnMessage(f func(data []byte, isText bool)) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.onMessage = f
+}
+
+// OnJSON sets the callback for received text frames, whose payload is passed as is, so that the caller decides upon
+// the type to unmarshal into. It is invoked after the callback of OnMessage, if any. Binary frames are ignored.
+func (ws *WebSocket) OnJSON(f func(raw json.RawMessage)) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.onJSON = f
+}
+
+// OnClose sets the callback, which is invoked once the connection has been closed, including a failed connection
+// attempt.
+func (ws *WebSocket) OnClose(f func(code int, reason string)) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	ws.onClose = f
+}
+
+// Send sends the data as a binary frame.
+func (ws *WebSocket) Send(data []byte) error {
+	buf := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(buf, data)
+
+	return ws.send(buf)
+}
+
+// SendText sends the text as a text frame.
+func (ws *WebSocket) SendText(text string) error {
+	return ws.send(js.ValueOf(text))
+}
+
+// SendJSON marshals the value and sends it as a text frame. A marshalling error is returned without sending anything.
+func (ws *WebSocket) SendJSON(v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return ws.SendText(string(buf))
+}
+
+// Close closes the connection normally. The close callback is still invoked.
+func (ws *WebSocket) Close() {
+	ws.mutex.Lock()
+	ws.pending = nil
+	ws.mutex.Unlock()
+
+	ws.value.Call("close")
+}
+
+// send transmits the value or buffers it while connecting.
+func (ws *WebSocket) send(v js.Value) error {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	switch ws.value.Get("readyState").Int() {
+	case wsConnecting:
+		ws.pending = append(ws.pending, v)
+
+		return nil
+	case wsOpen:
+		ws.value.Call("send", v)
+
+		return nil
+	default:
+		return ErrWebSocketClosed
+	}
+}
+
+// listen registers the handler for the given event of the browser WebSocket.
+func (ws *WebSocket) listen(event string, handler func(evt js.Value)) {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		handler(args[0])
+
+		return nil
+	})
+
+	ws.funcs = append(ws.funcs, fn)
+	ws.value.Call("addEventListener", event, fn)
+}
+
+// handleOpen flushes the messages sent while connecting and posts the open callback, see ReconnectingWebSocket.
+func (ws *WebSocket) handleOpen(js.Value) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	for _, v := range ws.pending {
+		ws.value.Call("send", v)
+	}
+
+	ws.pending = nil
+
+	if f := ws.onOpen; f != nil {
+		ws.queue.Post(func() {
+			defer fetch.GlobalPanicHandler()
+
+			f()
+		})
+	}
+}
+
+// handleMessage copies the data into the go heap and posts it to the callback.
+func (ws *WebSocket) handleMessage(evt js.Value) {
+	var (
+		data   []byte
+		isText bool
+	)
+
+	if v := evt.Get("data"); v.Type() == js.TypeString {
+		data, isText = []byte(v.String()), true
+	} else {
+		buf := js.Global().Get("Uint8Array").New(v)
+		data = make([]byte, buf.Get("length").Int())
+		js.CopyBytesToGo(data, buf)
+	}
+
+	ws.mutex.Lock()
+	f, onJSON := ws.onMessage, ws.onJSON
+	ws.mutex.Unlock()
+
+	if !isText {
+		onJSON = nil
+	}
+
+	if f == nil && onJSON == nil {
+		return
+	}
+
+	ws.queue.Post(func() {
+		defer fetch.GlobalPanicHandler()
+
+		if f != nil {
+			f(data, isText)
+		}
+
+		if onJSON != nil {
+			onJSON(data)
+		}
+	})
+}
+
+// handleClose posts the close callback and releases all resources.
+func (ws *WebSocket) handleClose(evt js.Value) {
+	code, reason := evt.Get("code").Int(), evt.Get("reason").String()
+
+	ws.mutex.Lock()
+	ws.wasClean = evt.Get("wasClean").Bool()
+	f := ws.onClose
+	funcs := ws.funcs
+	ws.funcs = nil
+	ws.pending = nil
+	ws.mutex.Unlock()
+
+	ws.queue.Post(func() {
+		defer fetch.GlobalPanicHandler()
+
+		if f != nil {
+			f(code, reason)
+		}
+	})
+
+	ws.queue.Post(ws.queue.Close)
+
+	// the event handler is still running, so release the functions afterwards
+	go func() {
+		for _, fn := range funcs {
+			fn.Release()
+		}
+	}()
+}